@@ -2,13 +2,37 @@ package main
 
 import (
 	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/archive"
+	"github.com/ztimes2/dailybugle/internal/argocd"
 	"github.com/ztimes2/dailybugle/internal/config"
+	"github.com/ztimes2/dailybugle/internal/deploylog"
+	"github.com/ztimes2/dailybugle/internal/discord"
+	"github.com/ztimes2/dailybugle/internal/email"
+	"github.com/ztimes2/dailybugle/internal/feed"
+	"github.com/ztimes2/dailybugle/internal/github"
+	"github.com/ztimes2/dailybugle/internal/gitlab"
+	"github.com/ztimes2/dailybugle/internal/gitlog"
 	"github.com/ztimes2/dailybugle/internal/google"
+	"github.com/ztimes2/dailybugle/internal/ics"
+	"github.com/ztimes2/dailybugle/internal/jenkins"
 	"github.com/ztimes2/dailybugle/internal/jira"
+	"github.com/ztimes2/dailybugle/internal/junit"
+	"github.com/ztimes2/dailybugle/internal/ledger"
+	"github.com/ztimes2/dailybugle/internal/matrix"
+	"github.com/ztimes2/dailybugle/internal/mattermost"
 	"github.com/ztimes2/dailybugle/internal/newspaper"
+	"github.com/ztimes2/dailybugle/internal/pagerduty"
+	"github.com/ztimes2/dailybugle/internal/rocketchat"
 	"github.com/ztimes2/dailybugle/internal/slack"
+	"github.com/ztimes2/dailybugle/internal/teams"
+	"github.com/ztimes2/dailybugle/internal/testhistory"
+	"github.com/ztimes2/dailybugle/internal/webhook"
 	"golang.org/x/oauth2"
 )
 
@@ -19,10 +43,29 @@ func main() {
 		return
 	}
 
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		handleError(errors.Wrap(err, "could not load timezone"))
+		return
+	}
+
+	// The newspaper is written in the configured timezone, so days of issues and
+	// times shown on pages are local to it.
+	newspaper.TimeNowFunc = func() time.Time {
+		return time.Now().In(location)
+	}
+
 	jiraClient, err := jira.New(jira.Config{
 		BaseURL:  cfg.JiraBaseURL,
 		Username: cfg.JiraUsername,
 		APIToken: cfg.JiraAPIToken,
+
+		IncidentsJQL:     cfg.JiraIncidentsJQL,
+		BugsJQL:          cfg.JiraBugsJQL,
+		EscapedBugsJQL:   cfg.JiraEscapedBugsJQL,
+		ReopenedStatus:   cfg.JiraReopenedStatus,
+		BoardID:          cfg.JiraBoardID,
+		StoryPointsField: cfg.JiraStoryPointsField,
 	})
 	if err != nil {
 		handleError(errors.Wrap(err, "could not init Jira client"))
@@ -35,20 +78,399 @@ func main() {
 		return
 	}
 
-	if err := newspaper.EditAndPublish(
-		slack.NewChannel(cfg.SlackAPIToken, cfg.SlackChannelID),
+	incidentSources := initIncidentSources(cfg, jiraClient)
+
+	writers := []newspaper.Writer{
 		newspaper.NewCodeReviewMarket(jiraClient),
-		newspaper.NewReleaseForecast(calendars...),
-	); err != nil {
+		newspaper.NewTrafficReport(
+			jiraClient, time.Duration(cfg.StaleTicketDays)*24*time.Hour,
+		),
+		newspaper.NewBugReport(jiraClient),
+		newspaper.NewReleaseForecast(calendars...).WithIncidents(incidentSources...),
+	}
+
+	// The Deployment Log follows the Release Forecast since both are about
+	// shipping.
+	if deploymentSources := initDeploymentSources(cfg); len(deploymentSources) > 0 {
+		writers = append(writers, newspaper.NewDeploymentLog(
+			cfg.JiraBaseURL, deploymentSources...,
+		))
+	}
+
+	weekday, err := parseWeekday(cfg.FlowMetricsWeekday)
+	if err != nil {
+		handleError(err)
+		return
+	}
+
+	writers = append(writers, newspaper.NewFlowMetrics(jiraClient, weekday))
+
+	if cfg.JiraBoardID != 0 {
+		writers = append(writers, newspaper.NewSprintProgress(jiraClient))
+	}
+
+	if cfg.GitRepositoryPath != "" {
+		repo, err := gitlog.New(cfg.GitRepositoryPath, cfg.GitTagPattern)
+		if err != nil {
+			handleError(errors.Wrap(err, "could not init git repository"))
+			return
+		}
+
+		writers = append(writers, newspaper.NewReleaseNotes(
+			cfg.JiraBaseURL, splitList(cfg.JiraProjectKeys), repo,
+		))
+	}
+
+	if schedules, fallbacks := initOnCallSchedules(cfg); len(schedules) > 0 {
+		writers = append(writers, newspaper.NewOnCallRoster(
+			schedules...,
+		).WithFallback(fallbacks...))
+	}
+
+	if len(incidentSources) > 0 {
+		writers = append(writers, newspaper.NewIncidentDigest(incidentSources...))
+	}
+
+	if buildSources := initBuildSources(cfg); len(buildSources) > 0 {
+		writers = append(writers, newspaper.NewPipelineHealth(
+			cfg.PipelineRuns, buildSources...,
+		))
+	}
+
+	if testResultSources := initTestResultSources(cfg); len(testResultSources) > 0 {
+		writers = append(writers, newspaper.NewFlakyTestReport(
+			time.Duration(cfg.FlakyTestWindowDays)*24*time.Hour,
+			testhistory.NewFile(cfg.TestHistoryPath, 0),
+			testResultSources...,
+		))
+	}
+
+	destinations, err := initDestinations(cfg)
+	if err != nil {
+		handleError(err)
+		return
+	}
+
+	deliveries, err := newspaper.EditAndDistribute(destinations, writers...)
+	if err != nil {
 		handleError(err)
 		return
 	}
+
+	for _, d := range deliveries {
+		log.Printf("%s: %s after %d attempt(s)", d.Destination, d.Status, d.Attempts)
+	}
+
+	if err := deliveries.Err(); err != nil {
+		handleError(err)
+		return
+	}
+}
+
+func parseWeekday(name string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), name) {
+			return d, nil
+		}
+	}
+	return 0, errors.Errorf("unknown weekday %q", name)
 }
 
 func handleError(err error) {
 	panic(err)
 }
 
+// splitList splits the given comma-separated list trimming spaces around its
+// items and dropping empty ones.
+func splitList(list string) []string {
+	var items []string
+
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func initDestinations(cfg config.Config) ([]newspaper.Destination, error) {
+	var destinations []newspaper.Destination
+
+	add := func(name string, p newspaper.Publisher) {
+		destinations = append(destinations, newspaper.Destination{
+			Name:        name,
+			Publisher:   p,
+			MaxAttempts: cfg.PublishAttempts,
+		})
+	}
+
+	// addRecorded adds a destination that is not able to tell whether it has
+	// already received an issue, so the ledger keeps track of it instead.
+	addRecorded := func(name string, p newspaper.Publisher) {
+		d := newspaper.Destination{
+			Name:        name,
+			Publisher:   p,
+			MaxAttempts: cfg.PublishAttempts,
+		}
+
+		if cfg.LedgerPath != "" {
+			d.Ledger = ledger.NewFile(cfg.LedgerPath)
+			d.Edition = cfg.Edition
+		}
+
+		destinations = append(destinations, d)
+	}
+
+	for _, channelID := range splitList(cfg.SlackChannelID) {
+		add("slack:"+channelID, initSlackChannel(cfg, channelID))
+	}
+
+	if cfg.TeamsWebhookURL != "" {
+		addRecorded("teams", teams.NewChannel(http.DefaultClient, cfg.TeamsWebhookURL))
+	}
+
+	if cfg.DiscordWebhookURL != "" {
+		addRecorded("discord", discord.NewWebhook(http.DefaultClient, cfg.DiscordWebhookURL))
+	}
+
+	if cfg.MattermostWebhookURL != "" {
+		addRecorded("mattermost", mattermost.NewWebhook(
+			http.DefaultClient, cfg.MattermostWebhookURL,
+		))
+	} else if cfg.MattermostBaseURL != "" {
+		addRecorded("mattermost", mattermost.NewChannel(
+			http.DefaultClient,
+			cfg.MattermostBaseURL, cfg.MattermostToken, cfg.MattermostChannelID,
+		))
+	}
+
+	if cfg.RocketChatWebhookURL != "" {
+		addRecorded("rocketchat", rocketchat.NewWebhook(
+			http.DefaultClient, cfg.RocketChatWebhookURL,
+		))
+	}
+
+	if cfg.MatrixHomeserverURL != "" {
+		addRecorded("matrix", matrix.NewRoom(
+			http.DefaultClient,
+			cfg.MatrixHomeserverURL, cfg.MatrixAccessToken, cfg.MatrixRoomID,
+			cfg.Edition,
+		))
+	}
+
+	if cfg.GoogleChatWebhookURL != "" {
+		addRecorded("googlechat", google.NewChatSpace(
+			http.DefaultClient, cfg.GoogleChatWebhookURL, cfg.JiraBaseURL,
+		))
+	}
+
+	webhookURLs := splitList(cfg.WebhookURLs)
+	if len(webhookURLs) > 0 && cfg.WebhookSecret == "" {
+		return nil, errors.New("webhook secret is not configured")
+	}
+
+	for _, url := range webhookURLs {
+		addRecorded("webhook:"+url, webhook.NewEndpoint(
+			http.DefaultClient, url, cfg.WebhookSecret,
+		))
+	}
+
+	if cfg.SMTPHost != "" {
+		recipients := splitList(cfg.EmailRecipients)
+		if len(recipients) == 0 {
+			return nil, errors.New("email recipients are not configured")
+		}
+
+		addRecorded("email", email.NewMailer(email.Config{
+			Host:       cfg.SMTPHost,
+			Port:       cfg.SMTPPort,
+			Username:   cfg.SMTPUsername,
+			Password:   cfg.SMTPPassword,
+			From:       cfg.EmailFrom,
+			Recipients: recipients,
+		}))
+	}
+
+	if cfg.ArchiveDir != "" {
+		add("archive", archive.New(cfg.ArchiveDir))
+	}
+
+	if cfg.FeedAtomPath != "" {
+		f, err := feed.New(feed.Config{
+			BaseURL:  cfg.FeedBaseURL,
+			AtomPath: cfg.FeedAtomPath,
+			RSSPath:  cfg.FeedRSSPath,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "could not init feed")
+		}
+
+		add("feed", f)
+	}
+
+	return destinations, nil
+}
+
+func initSlackChannel(cfg config.Config, channelID string) slack.Channel {
+	opts := []slack.ChannelOption{
+		slack.WithThreads(cfg.SlackThreads),
+	}
+
+	if cfg.LedgerPath != "" {
+		opts = append(opts,
+			slack.WithLedger(ledger.NewFile(cfg.LedgerPath), cfg.Edition),
+			slack.WithForce(cfg.ForcePublish),
+		)
+	}
+
+	return slack.NewChannel(cfg.SlackAPIToken, channelID, opts...)
+}
+
+// initOnCallSchedules initializes on-call schedules from PagerDuty along with
+// iCalendar feeds to fall back to when PagerDuty fails. iCalendar feeds are used
+// on their own when PagerDuty is not configured.
+func initOnCallSchedules(cfg config.Config,
+) (schedules, fallbacks []newspaper.OnCallSchedule) {
+
+	icsSchedules := initICSSchedules(cfg)
+
+	if cfg.PagerDutyAPIToken != "" {
+		return []newspaper.OnCallSchedule{initPagerDuty(cfg)}, icsSchedules
+	}
+
+	return icsSchedules, nil
+}
+
+func initICSSchedules(cfg config.Config) []newspaper.OnCallSchedule {
+	var schedules []newspaper.OnCallSchedule
+
+	if cfg.OnCallICSPrimaryURL != "" {
+		schedules = append(schedules, ics.NewSchedule(
+			http.DefaultClient, cfg.OnCallICSPrimaryURL, cfg.OnCallICSPolicy, 1,
+		))
+	}
+
+	if cfg.OnCallICSSecondaryURL != "" {
+		schedules = append(schedules, ics.NewSchedule(
+			http.DefaultClient, cfg.OnCallICSSecondaryURL, cfg.OnCallICSPolicy, 2,
+		))
+	}
+
+	return schedules
+}
+
+// initIncidentSources initializes incident sources of PagerDuty and Jira that are
+// configured. Incidents are fetched from them once and shared by all writers.
+func initIncidentSources(cfg config.Config, j jira.Jira) []newspaper.IncidentSource {
+	var sources []newspaper.IncidentSource
+
+	if cfg.PagerDutyAPIToken != "" {
+		sources = append(sources, initPagerDuty(cfg))
+	}
+
+	if cfg.JiraIncidentsJQL != "" {
+		sources = append(sources, j)
+	}
+
+	if len(sources) == 0 {
+		return nil
+	}
+
+	return []newspaper.IncidentSource{
+		newspaper.NewIncidentCache(sources...),
+	}
+}
+
+// initBuildSources initializes build sources of CI services that are configured.
+func initBuildSources(cfg config.Config) []newspaper.BuildSource {
+	var sources []newspaper.BuildSource
+
+	if cfg.GitHubRepositories != "" {
+		sources = append(sources, initGitHubActions(cfg))
+	}
+
+	if cfg.GitLabProjects != "" {
+		sources = append(sources, gitlab.New(http.DefaultClient, gitlab.Config{
+			BaseURL:  cfg.GitLabBaseURL,
+			Token:    cfg.GitLabToken,
+			Projects: splitList(cfg.GitLabProjects),
+		}))
+	}
+
+	if cfg.JenkinsBaseURL != "" && cfg.JenkinsJobs != "" {
+		sources = append(sources, jenkins.New(http.DefaultClient, jenkins.Config{
+			BaseURL:  cfg.JenkinsBaseURL,
+			Username: cfg.JenkinsUsername,
+			APIToken: cfg.JenkinsAPIToken,
+			Jobs:     splitList(cfg.JenkinsJobs),
+		}))
+	}
+
+	return sources
+}
+
+// initTestResultSources initializes sources of JUnit XML reports that are
+// configured.
+func initTestResultSources(cfg config.Config) []newspaper.TestResultSource {
+	var sources []newspaper.TestResultSource
+
+	if cfg.TestReportsDir != "" {
+		sources = append(sources, junit.NewDirectory(cfg.TestReportsDir))
+	}
+
+	if cfg.GitHubRepositories != "" && cfg.GitHubTestReportsArtifact != "" {
+		sources = append(sources, initGitHubActions(cfg))
+	}
+
+	return sources
+}
+
+// initDeploymentSources initializes deployment sources that are configured.
+func initDeploymentSources(cfg config.Config) []newspaper.DeploymentSource {
+	var sources []newspaper.DeploymentSource
+
+	if cfg.GitHubDeploymentRepositories != "" {
+		sources = append(sources, github.NewDeployments(
+			http.DefaultClient, github.DeploymentsConfig{
+				Token:        cfg.GitHubToken,
+				Repositories: splitList(cfg.GitHubDeploymentRepositories),
+				Environments: splitList(cfg.GitHubDeploymentEnvironments),
+			},
+		))
+	}
+
+	if cfg.ArgoCDBaseURL != "" {
+		sources = append(sources, argocd.New(http.DefaultClient, argocd.Config{
+			BaseURL:      cfg.ArgoCDBaseURL,
+			Token:        cfg.ArgoCDToken,
+			Applications: splitList(cfg.ArgoCDApplications),
+		}))
+	}
+
+	if cfg.DeploymentLogPath != "" {
+		sources = append(sources, deploylog.NewFile(cfg.DeploymentLogPath))
+	}
+
+	return sources
+}
+
+func initGitHubActions(cfg config.Config) github.Actions {
+	return github.NewActions(http.DefaultClient, github.ActionsConfig{
+		Token:               cfg.GitHubToken,
+		Repositories:        splitList(cfg.GitHubRepositories),
+		Workflow:            cfg.GitHubWorkflow,
+		TestReportsArtifact: cfg.GitHubTestReportsArtifact,
+	})
+}
+
+func initPagerDuty(cfg config.Config) pagerduty.PagerDuty {
+	return pagerduty.New(http.DefaultClient, pagerduty.Config{
+		APIToken:            cfg.PagerDutyAPIToken,
+		EscalationPolicyIDs: splitList(cfg.PagerDutyEscalationPolicyIDs),
+	})
+}
+
 func initCalendars(cfg config.Config) ([]newspaper.Calendar, error) {
 	var token oauth2.Token
 	if err := json.Unmarshal([]byte(cfg.GoogleAccessToken), &token); err != nil {
@@ -5,6 +5,7 @@ go 1.15
 require (
 	github.com/andygrunwald/go-jira v1.13.0
 	github.com/dustin/go-humanize v1.0.0
+	github.com/go-git/go-git/v5 v5.2.0
 	github.com/heetch/confita v0.9.2
 	github.com/joho/godotenv v1.3.0
 	github.com/pkg/errors v0.9.1
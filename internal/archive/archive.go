@@ -0,0 +1,397 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/atomicfile"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+	"github.com/ztimes2/dailybugle/internal/render"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	indexFileName      = "index.html"
+	stylesheetFileName = "style.css"
+	issuesFileName     = "issues.json"
+)
+
+// Archive provides functionality for keeping a browsable archive of the
+// newspaper's issues as static HTML pages in a directory. Each issue is placed
+// under its own permalink (e.g. 2021/02/15/index.html) and is listed on the
+// archive's index page.
+type Archive struct {
+	dir string
+}
+
+// New initializes a new Archive.
+func New(dir string) Archive {
+	return Archive{
+		dir: dir,
+	}
+}
+
+// storedIssue represents an issue kept in the archive. Contents of the pages are
+// kept already rendered so that the archive can be rebuilt at any time.
+type storedIssue struct {
+	Date        string       `json:"date"`
+	PublishedAt time.Time    `json:"published_at"`
+	Pages       []storedPage `json:"pages"`
+}
+
+type storedPage struct {
+	Headline string          `json:"headline"`
+	Content  []template.HTML `json:"content"`
+	Author   string          `json:"author"`
+}
+
+func (i storedIssue) permalink() string {
+	date, _ := time.Parse(dateLayout, i.Date)
+	return Permalink(date)
+}
+
+// Permalink returns the path of the issue published at the given time relative
+// to the archive's root (e.g. 2021/02/15/).
+func Permalink(t time.Time) string {
+	return t.Format("2006/01/02") + "/"
+}
+
+// Publish implements newspaper.Publisher interface and adds the given newspaper
+// issue to the archive. An issue published on a day that is already archived
+// replaces the previous one.
+func (a Archive) Publish(issue newspaper.Issue) error {
+	issues, err := a.readIssues()
+	if err != nil {
+		return errors.Wrap(err, "could not read archived issues")
+	}
+
+	now := newspaper.TimeNowFunc()
+
+	stored := storedIssue{
+		Date:        now.Format(dateLayout),
+		PublishedAt: now,
+	}
+
+	for _, p := range render.HTMLPages(issue) {
+		stored.Pages = append(stored.Pages, storedPage(p))
+	}
+
+	issues = upsertIssue(issues, stored)
+
+	if err := a.writeIssues(issues); err != nil {
+		return errors.Wrap(err, "could not write archived issues")
+	}
+
+	if err := a.render(issues); err != nil {
+		return errors.Wrap(err, "could not render archive")
+	}
+
+	return nil
+}
+
+// IsIdempotent implements newspaper.IdempotentPublisher interface. Publishing an
+// issue again replaces the one archived on the same day.
+func (a Archive) IsIdempotent() bool {
+	return true
+}
+
+// upsertIssue adds the given issue to the list replacing an issue of the same day
+// and keeps the list sorted from the oldest to the newest issue.
+func upsertIssue(issues []storedIssue, issue storedIssue) []storedIssue {
+	replaced := false
+
+	for i := range issues {
+		if issues[i].Date == issue.Date {
+			issues[i] = issue
+			replaced = true
+			break
+		}
+	}
+
+	if !replaced {
+		issues = append(issues, issue)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Date < issues[j].Date
+	})
+
+	return issues
+}
+
+func (a Archive) readIssues() ([]storedIssue, error) {
+	data, err := ioutil.ReadFile(filepath.Join(a.dir, issuesFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var issues []storedIssue
+	if err := json.Unmarshal(data, &issues); err != nil {
+		return nil, err
+	}
+
+	return issues, nil
+}
+
+func (a Archive) writeIssues(issues []storedIssue) error {
+	data, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(a.dir, 0755); err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(filepath.Join(a.dir, issuesFileName), data, 0644)
+}
+
+// render writes the stylesheet, the index page and a page per issue. Pages of all
+// issues are rendered again since the navigation between them may have changed.
+func (a Archive) render(issues []storedIssue) error {
+	if err := atomicfile.WriteFile(
+		filepath.Join(a.dir, stylesheetFileName), []byte(stylesheet), 0644,
+	); err != nil {
+		return err
+	}
+
+	var index indexData
+
+	// Lists issues from the newest to the oldest one.
+	for i := len(issues) - 1; i >= 0; i-- {
+		index.Issues = append(index.Issues, toIssueLink(issues[i], ""))
+	}
+
+	if err := renderFile(
+		filepath.Join(a.dir, indexFileName), indexTemplate, index,
+	); err != nil {
+		return err
+	}
+
+	for i, issue := range issues {
+		// Issue pages are nested three directories deep (year/month/day).
+		const root = "../../../"
+
+		data := issueData{
+			Root:  root,
+			Title: formatDate(issue.Date),
+			Pages: issue.Pages,
+		}
+
+		if i > 0 {
+			prev := toIssueLink(issues[i-1], root)
+			data.Previous = &prev
+		}
+
+		if i < len(issues)-1 {
+			next := toIssueLink(issues[i+1], root)
+			data.Next = &next
+		}
+
+		dir := filepath.Join(a.dir, filepath.FromSlash(issue.permalink()))
+
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+
+		if err := renderFile(
+			filepath.Join(dir, indexFileName), issueTemplate, data,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderFile renders the template into a buffer first, so that a failed
+// rendering does not leave a page half-written.
+func renderFile(path string, t *template.Template, data interface{}) error {
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(path, b.Bytes(), 0644)
+}
+
+type issueLink struct {
+	URL       string
+	Title     string
+	Headlines []string
+}
+
+func toIssueLink(issue storedIssue, root string) issueLink {
+	l := issueLink{
+		URL:   root + issue.permalink(),
+		Title: formatDate(issue.Date),
+	}
+
+	for _, p := range issue.Pages {
+		l.Headlines = append(l.Headlines, p.Headline)
+	}
+
+	return l
+}
+
+func formatDate(date string) string {
+	t, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return date
+	}
+	return t.Format("Monday, 2 January 2006")
+}
+
+type indexData struct {
+	Issues []issueLink
+}
+
+type issueData struct {
+	Root     string
+	Title    string
+	Pages    []storedPage
+	Previous *issueLink
+	Next     *issueLink
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>The Daily Bugle Archive</title>
+<link rel="stylesheet" href="style.css">
+</head>
+<body>
+<main>
+<h1 class="masthead">The Daily Bugle</h1>
+<p class="dateline">Archive of past issues</p>
+{{if .Issues}}<ul class="issues">
+{{range .Issues}}<li>
+<a href="{{.URL}}">{{.Title}}</a>
+<span class="headlines">{{range $i, $h := .Headlines}}{{if $i}} · {{end}}{{$h}}{{end}}</span>
+</li>
+{{end}}</ul>
+{{else}}<p>No issues have been published yet.</p>
+{{end}}</main>
+</body>
+</html>
+`))
+
+var issueTemplate = template.Must(template.New("issue").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>The Daily Bugle, {{.Title}}</title>
+<link rel="stylesheet" href="{{.Root}}style.css">
+</head>
+<body>
+<main>
+<h1 class="masthead"><a href="{{.Root}}index.html">The Daily Bugle</a></h1>
+<p class="dateline">{{.Title}}</p>
+{{range .Pages}}<article>
+<h2>{{.Headline}}</h2>
+{{range .Content}}<p>{{.}}</p>
+{{end}}<p class="byline">By {{.Author}}</p>
+</article>
+{{end}}<nav>
+{{with .Previous}}<a class="previous" href="{{.URL}}">&larr; {{.Title}}</a>{{end}}
+<a class="archive" href="{{.Root}}index.html">All issues</a>
+{{with .Next}}<a class="next" href="{{.URL}}">{{.Title}} &rarr;</a>{{end}}
+</nav>
+</main>
+</body>
+</html>
+`))
+
+const stylesheet = `body {
+  margin: 0;
+  padding: 24px;
+  background: #f4f1ea;
+  color: #222;
+  font-family: Georgia, "Times New Roman", serif;
+}
+
+main {
+  max-width: 720px;
+  margin: 0 auto;
+  padding: 24px 32px;
+  background: #fff;
+}
+
+a {
+  color: #8b0000;
+}
+
+.masthead {
+  margin: 0;
+  font-size: 40px;
+  text-align: center;
+  text-transform: uppercase;
+  letter-spacing: 2px;
+}
+
+.masthead a {
+  color: inherit;
+  text-decoration: none;
+}
+
+.dateline {
+  margin: 8px 0 24px;
+  padding: 6px 0;
+  border-top: 1px solid #222;
+  border-bottom: 3px double #222;
+  text-align: center;
+  font-style: italic;
+}
+
+article {
+  padding-bottom: 16px;
+  border-bottom: 1px solid #ddd;
+}
+
+article p {
+  white-space: pre-wrap;
+  line-height: 1.5;
+}
+
+.byline {
+  color: #777;
+  font-size: 13px;
+  font-style: italic;
+}
+
+nav {
+  display: flex;
+  justify-content: space-between;
+  margin-top: 24px;
+}
+
+.issues {
+  padding: 0;
+  list-style: none;
+}
+
+.issues li {
+  padding: 8px 0;
+  border-bottom: 1px solid #eee;
+}
+
+.headlines {
+  display: block;
+  color: #777;
+  font-size: 14px;
+}
+`
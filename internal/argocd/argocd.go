@@ -0,0 +1,158 @@
+package argocd
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/httpjson"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+const (
+	// environmentLabel is the label of applications naming the environment they
+	// are deployed to.
+	environmentLabel = "environment"
+
+	// shortRevisionLength is the length Git revisions are abbreviated to.
+	shortRevisionLength = 7
+)
+
+// ArgoCD provides communication with Argo CD REST API.
+type ArgoCD struct {
+	client       *http.Client
+	baseURL      string
+	token        string
+	applications []string
+}
+
+// Config holds ArgoCD's configuration.
+type Config struct {
+	BaseURL string
+	Token   string
+
+	// Applications limits deployments to the given applications. Deployments of
+	// all applications are fetched when empty.
+	Applications []string
+}
+
+// New initializes a new ArgoCD.
+func New(c *http.Client, conf Config) ArgoCD {
+	return ArgoCD{
+		client:       c,
+		baseURL:      strings.TrimSuffix(conf.BaseURL, "/"),
+		token:        conf.Token,
+		applications: conf.Applications,
+	}
+}
+
+type applicationsResponse struct {
+	Items []struct {
+		Metadata struct {
+			Name   string            `json:"name"`
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			Destination struct {
+				Namespace string `json:"namespace"`
+			} `json:"destination"`
+		} `json:"spec"`
+		Status struct {
+			History []struct {
+				Revision    string    `json:"revision"`
+				DeployedAt  time.Time `json:"deployedAt"`
+				InitiatedBy struct {
+					Username  string `json:"username"`
+					Automated bool   `json:"automated"`
+				} `json:"initiatedBy"`
+				Source struct {
+					TargetRevision string `json:"targetRevision"`
+				} `json:"source"`
+			} `json:"history"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// GetDeploymentsSince implements newspaper.DeploymentSource interface and fetches
+// syncs from history of the applications deployed since the given time.
+// Applications are considered deployed to the environment of their "environment"
+// label or, when missing, to their destination namespace.
+func (a ArgoCD) GetDeploymentsSince(t time.Time) ([]newspaper.Deployment, error) {
+	var resp applicationsResponse
+	if err := a.get("/api/v1/applications", nil, &resp); err != nil {
+		return nil, errors.Wrap(err, "could not fetch applications")
+	}
+
+	var deployments []newspaper.Deployment
+
+	for _, app := range resp.Items {
+		if !a.isWatched(app.Metadata.Name) {
+			continue
+		}
+
+		env := app.Metadata.Labels[environmentLabel]
+		if env == "" {
+			env = app.Spec.Destination.Namespace
+		}
+
+		for _, h := range app.Status.History {
+			if h.DeployedAt.Before(t) {
+				continue
+			}
+
+			version := h.Source.TargetRevision
+			if version == "" || version == "HEAD" {
+				version = h.Revision
+				if len(version) > shortRevisionLength {
+					version = version[:shortRevisionLength]
+				}
+			}
+
+			deployer := h.InitiatedBy.Username
+			if h.InitiatedBy.Automated {
+				deployer = "auto-sync"
+			}
+
+			deployments = append(deployments, newspaper.Deployment{
+				Service:     app.Metadata.Name,
+				Environment: env,
+				Version:     version,
+				Deployer:    deployer,
+				URL:         a.baseURL + "/applications/" + app.Metadata.Name,
+				TicketIDs:   newspaper.ParseTicketIDs(h.Source.TargetRevision),
+				DeployedAt:  h.DeployedAt,
+			})
+		}
+	}
+
+	return deployments, nil
+}
+
+func (a ArgoCD) isWatched(application string) bool {
+	if len(a.applications) == 0 {
+		return true
+	}
+
+	for _, name := range a.applications {
+		if name == application {
+			return true
+		}
+	}
+
+	return false
+}
+
+// get sends a GET request to the given path of the API and decodes the response
+// into v.
+func (a ArgoCD) get(path string, query url.Values, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, a.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return errors.Wrap(err, "could not prepare request")
+	}
+
+	req.Header.Set("Authorization", "Bearer "+a.token)
+
+	return httpjson.Do(a.client, req, v)
+}
@@ -0,0 +1,38 @@
+// Package atomicfile provides replacing of files in a way that a crash in the
+// middle of writing does not leave them corrupted.
+package atomicfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes the given data to a temporary file next to the file of the
+// given path and renames it over the file, so that readers observe either the
+// previous or the new content of the file but never a part of it. The file gets
+// the given permissions, the same way as with ioutil.WriteFile.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	// Temporary files are only accessible by their owner.
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -18,16 +18,123 @@ type Config struct {
 	DevMilestonesCalendarID string `config:"DEVMILESTONES_CALENDAR_ID,required"`
 
 	SlackAPIToken  string `config:"SLACK_API_TOKEN,required"`
-	SlackChannelID string `config:"SLACK_CHANNEL_ID,required"`
+	SlackChannelID string `config:"SLACK_CHANNEL_ID,required"` // May contain several comma-separated IDs.
+	SlackThreads   bool   `config:"SLACK_THREADS"`
+
+	TeamsWebhookURL   string `config:"TEAMS_WEBHOOK_URL"`
+	DiscordWebhookURL string `config:"DISCORD_WEBHOOK_URL"`
+
+	MattermostWebhookURL string `config:"MATTERMOST_WEBHOOK_URL"`
+	MattermostBaseURL    string `config:"MATTERMOST_BASE_URL"`
+	MattermostToken      string `config:"MATTERMOST_TOKEN"`
+	MattermostChannelID  string `config:"MATTERMOST_CHANNEL_ID"`
+	RocketChatWebhookURL string `config:"ROCKETCHAT_WEBHOOK_URL"`
+
+	MatrixHomeserverURL string `config:"MATRIX_HOMESERVER_URL"`
+	MatrixAccessToken   string `config:"MATRIX_ACCESS_TOKEN"`
+	MatrixRoomID        string `config:"MATRIX_ROOM_ID"`
+
+	GoogleChatWebhookURL string `config:"GOOGLE_CHAT_WEBHOOK_URL"`
+
+	// WebhookURLs may contain several comma-separated URLs.
+	WebhookURLs   string `config:"WEBHOOK_URLS"`
+	WebhookSecret string `config:"WEBHOOK_SECRET"`
+
+	SMTPHost        string `config:"SMTP_HOST"`
+	SMTPPort        int    `config:"SMTP_PORT"`
+	SMTPUsername    string `config:"SMTP_USERNAME"`
+	SMTPPassword    string `config:"SMTP_PASSWORD"`
+	EmailFrom       string `config:"EMAIL_FROM"`
+	EmailRecipients string `config:"EMAIL_RECIPIENTS"`
+
+	ArchiveDir string `config:"ARCHIVE_DIR"`
+
+	FeedBaseURL  string `config:"FEED_BASE_URL"`
+	FeedAtomPath string `config:"FEED_ATOM_PATH"`
+	FeedRSSPath  string `config:"FEED_RSS_PATH"`
 
 	JiraBaseURL  string `config:"JIRA_BASE_URL,required"`
 	JiraUsername string `config:"JIRA_USERNAME,required"`
 	JiraAPIToken string `config:"JIRA_API_TOKEN,required"`
+
+	JiraIncidentsJQL     string `config:"JIRA_INCIDENTS_JQL"`
+	JiraBugsJQL          string `config:"JIRA_BUGS_JQL"`
+	JiraEscapedBugsJQL   string `config:"JIRA_ESCAPED_BUGS_JQL"`
+	JiraReopenedStatus   string `config:"JIRA_REOPENED_STATUS"`
+	JiraBoardID          int    `config:"JIRA_BOARD_ID"`
+	JiraStoryPointsField string `config:"JIRA_STORY_POINTS_FIELD"`
+	// JiraProjectKeys may contain several comma-separated project keys (e.g. MB).
+	// Release notes link anything that looks like a ticket ID when empty.
+	JiraProjectKeys string `config:"JIRA_PROJECT_KEYS"`
+
+	StaleTicketDays int `config:"STALE_TICKET_DAYS"`
+
+	// FlowMetricsWeekday is the day of the week flow metrics are published on
+	// (e.g. Monday).
+	FlowMetricsWeekday string `config:"FLOW_METRICS_WEEKDAY"`
+
+	Timezone string `config:"TIMEZONE"`
+
+	PagerDutyAPIToken            string `config:"PAGERDUTY_API_TOKEN"`
+	PagerDutyEscalationPolicyIDs string `config:"PAGERDUTY_ESCALATION_POLICY_IDS"`
+
+	OnCallICSPolicy       string `config:"ONCALL_ICS_POLICY"`
+	OnCallICSPrimaryURL   string `config:"ONCALL_ICS_PRIMARY_URL"`
+	OnCallICSSecondaryURL string `config:"ONCALL_ICS_SECONDARY_URL"`
+
+	GitHubToken        string `config:"GITHUB_TOKEN"`
+	GitHubRepositories string `config:"GITHUB_REPOSITORIES"`
+	GitHubWorkflow     string `config:"GITHUB_WORKFLOW"`
+
+	GitHubTestReportsArtifact string `config:"GITHUB_TEST_REPORTS_ARTIFACT"`
+
+	GitHubDeploymentRepositories string `config:"GITHUB_DEPLOYMENT_REPOSITORIES"`
+	GitHubDeploymentEnvironments string `config:"GITHUB_DEPLOYMENT_ENVIRONMENTS"`
+
+	GitLabBaseURL  string `config:"GITLAB_BASE_URL"`
+	GitLabToken    string `config:"GITLAB_TOKEN"`
+	GitLabProjects string `config:"GITLAB_PROJECTS"`
+
+	JenkinsBaseURL  string `config:"JENKINS_BASE_URL"`
+	JenkinsUsername string `config:"JENKINS_USERNAME"`
+	JenkinsAPIToken string `config:"JENKINS_API_TOKEN"`
+	JenkinsJobs     string `config:"JENKINS_JOBS"`
+
+	PipelineRuns int `config:"PIPELINE_RUNS"`
+
+	ArgoCDBaseURL      string `config:"ARGOCD_BASE_URL"`
+	ArgoCDToken        string `config:"ARGOCD_TOKEN"`
+	ArgoCDApplications string `config:"ARGOCD_APPLICATIONS"`
+
+	DeploymentLogPath string `config:"DEPLOYMENT_LOG_PATH"`
+
+	GitRepositoryPath string `config:"GIT_REPOSITORY_PATH"`
+	GitTagPattern     string `config:"GIT_TAG_PATTERN"`
+
+	TestReportsDir      string `config:"TEST_REPORTS_DIR"`
+	TestHistoryPath     string `config:"TEST_HISTORY_PATH"`
+	FlakyTestWindowDays int    `config:"FLAKY_TEST_WINDOW_DAYS"`
+
+	LedgerPath   string `config:"LEDGER_PATH"`
+	Edition      string `config:"EDITION"`
+	ForcePublish bool   `config:"FORCE_PUBLISH"`
+
+	PublishAttempts int `config:"PUBLISH_ATTEMPTS"`
 }
 
 // Load loads the application's configuration.
 func Load() (Config, error) {
-	var cfg Config
+	cfg := Config{
+		Edition:  "daily",
+		Timezone: "Asia/Singapore",
+		SMTPPort: 587,
+
+		PublishAttempts: 3,
+		OnCallICSPolicy: "On-call",
+		TestHistoryPath: "test-history.json",
+
+		FlowMetricsWeekday: "Monday",
+	}
 
 	if err := confita.NewLoader(
 		env.NewBackend(),
@@ -0,0 +1,102 @@
+package deploylog
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+// File provides functionality for reading deployments from a local JSON-lines
+// file that deploy scripts append a line to after every deployment, e.g.:
+//
+//	{"service":"api","environment":"production","version":"v1.4.2","deployer":"jane","tickets":["MB-123"],"url":"https://ci.example.com/builds/42","deployed_at":"2021-02-15T09:30:00+08:00"}
+//
+// Tickets mentioned in the version are picked up as well.
+type File struct {
+	path string
+}
+
+// NewFile initializes a new File.
+func NewFile(path string) File {
+	return File{
+		path: path,
+	}
+}
+
+type line struct {
+	Service     string    `json:"service"`
+	Environment string    `json:"environment"`
+	Version     string    `json:"version"`
+	Deployer    string    `json:"deployer"`
+	Tickets     []string  `json:"tickets"`
+	URL         string    `json:"url"`
+	DeployedAt  time.Time `json:"deployed_at"`
+}
+
+// GetDeploymentsSince implements newspaper.DeploymentSource interface and reads
+// deployments made since the given time. A missing file means that nothing has
+// been deployed yet.
+func (f File) GetDeploymentsSince(t time.Time) ([]newspaper.Deployment, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "could not open deployment log")
+	}
+	defer file.Close()
+
+	var deployments []newspaper.Deployment
+
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var l line
+		if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+			return nil, errors.Wrapf(err, "could not parse line %d of deployment log", n)
+		}
+
+		if l.DeployedAt.Before(t) {
+			continue
+		}
+
+		tickets := l.Tickets
+		for _, id := range newspaper.ParseTicketIDs(l.Version) {
+			if !contains(tickets, id) {
+				tickets = append(tickets, id)
+			}
+		}
+
+		deployments = append(deployments, newspaper.Deployment{
+			Service:     l.Service,
+			Environment: l.Environment,
+			Version:     l.Version,
+			Deployer:    l.Deployer,
+			URL:         l.URL,
+			TicketIDs:   tickets,
+			DeployedAt:  l.DeployedAt,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "could not read deployment log")
+	}
+
+	return deployments, nil
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
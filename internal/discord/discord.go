@@ -0,0 +1,180 @@
+package discord
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/httpjson"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+	"github.com/ztimes2/dailybugle/internal/render"
+)
+
+// Limits of Discord's embeds. See https://discord.com/developers/docs/resources/channel#embed-limits.
+const (
+	maxEmbedsPerMessage     = 10
+	maxCharactersPerMessage = 6000
+	maxTitleLength          = 256
+	maxDescriptionLength    = 4096
+	maxFooterLength         = 2048
+)
+
+// embedColor is the color of the stripe next to the embeds.
+const embedColor = 0xC62828
+
+// Webhook provides communication with a certain Discord channel through its
+// webhook.
+type Webhook struct {
+	client *http.Client
+	url    string
+}
+
+// NewWebhook initializes a new Webhook.
+func NewWebhook(c *http.Client, url string) Webhook {
+	return Webhook{
+		client: c,
+		url:    url,
+	}
+}
+
+// Publish implements newspaper.Publisher interface and publishes the given
+// newspaper issue to the Discord channel with one embed per page. An issue
+// exceeding Discord's limits is split into several messages.
+func (w Webhook) Publish(issue newspaper.Issue) error {
+	for _, embeds := range splitIntoMessages(toEmbeds(issue)) {
+		if err := w.execute(message{Embeds: embeds}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// execute sends the given message to the webhook. If Discord asks to slow down,
+// then the message is sent once again after the requested delay.
+func (w Webhook) execute(m message) error {
+	for attempt := 0; ; attempt++ {
+		err := httpjson.Post(w.client, w.url, m)
+
+		var respErr *httpjson.ResponseError
+		if errors.As(err, &respErr) &&
+			respErr.StatusCode == http.StatusTooManyRequests && attempt == 0 {
+
+			time.Sleep(getRetryAfter(respErr.Header))
+			continue
+		}
+
+		return err
+	}
+}
+
+func getRetryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.ParseFloat(header.Get("Retry-After"), 64)
+	if err != nil {
+		return time.Second
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+type message struct {
+	Embeds []embed `json:"embeds"`
+}
+
+type embed struct {
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Color       int     `json:"color"`
+	Footer      *footer `json:"footer,omitempty"`
+}
+
+type footer struct {
+	Text string `json:"text"`
+}
+
+// length returns the number of characters of the embed counting towards Discord's
+// limit of characters per message.
+func (e embed) length() int {
+	n := utf8.RuneCountInString(e.Title) + utf8.RuneCountInString(e.Description)
+	if e.Footer != nil {
+		n += utf8.RuneCountInString(e.Footer.Text)
+	}
+	return n
+}
+
+// toEmbeds turns the given newspaper issue into Discord embeds. Each page gets its
+// own embed unless its content is too long for a single one, in which case the
+// content is continued in the following embeds.
+func toEmbeds(issue newspaper.Issue) []embed {
+	var embeds []embed
+
+	for _, page := range issue {
+		title := render.Headline(page)
+
+		var content string
+		for i, text := range page.ContentTexts() {
+			if i > 0 {
+				content += "\n\n"
+			}
+			content += mrkdwn.Markdown(text)
+		}
+
+		chunks := mrkdwn.Chunk(content, maxDescriptionLength)
+		if len(chunks) == 0 {
+			chunks = []string{""}
+		}
+
+		for i, chunk := range chunks {
+			e := embed{
+				Title:       render.Truncate(title, maxTitleLength),
+				Description: chunk,
+				Color:       embedColor,
+			}
+
+			if i > 0 {
+				e.Title = ""
+			}
+
+			if i == len(chunks)-1 {
+				e.Footer = &footer{
+					Text: render.Truncate("By "+page.AuthorName, maxFooterLength),
+				}
+			}
+
+			embeds = append(embeds, e)
+		}
+	}
+
+	return embeds
+}
+
+// splitIntoMessages packs the given embeds into messages that do not exceed
+// Discord's limits of embeds and characters per message.
+func splitIntoMessages(embeds []embed) [][]embed {
+	var (
+		messages [][]embed
+		current  []embed
+		length   int
+	)
+
+	for _, e := range embeds {
+		if len(current) == maxEmbedsPerMessage ||
+			(len(current) > 0 && length+e.length() > maxCharactersPerMessage) {
+
+			messages = append(messages, current)
+			current = nil
+			length = 0
+		}
+
+		current = append(current, e)
+		length += e.length()
+	}
+
+	if len(current) > 0 {
+		messages = append(messages, current)
+	}
+
+	return messages
+}
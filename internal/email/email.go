@@ -0,0 +1,191 @@
+package email
+
+import (
+	"bytes"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+	"github.com/ztimes2/dailybugle/internal/render"
+)
+
+// Mailer provides functionality for sending the newspaper's issues by email
+// through an SMTP server.
+type Mailer struct {
+	conf Config
+}
+
+// Config holds Mailer's configuration.
+type Config struct {
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	From       string
+	Recipients []string
+}
+
+// NewMailer initializes a new Mailer.
+func NewMailer(conf Config) Mailer {
+	return Mailer{
+		conf: conf,
+	}
+}
+
+// Publish implements newspaper.Publisher interface and sends the given newspaper
+// issue to the recipients as a multipart email containing HTML and plain-text
+// versions of the issue. The connection is upgraded with STARTTLS whenever the
+// server supports it.
+func (m Mailer) Publish(issue newspaper.Issue) error {
+	msg, err := m.compose(issue)
+	if err != nil {
+		return errors.Wrap(err, "could not compose email")
+	}
+
+	var auth smtp.Auth
+	if m.conf.Username != "" {
+		auth = smtp.PlainAuth("", m.conf.Username, m.conf.Password, m.conf.Host)
+	}
+
+	if err := smtp.SendMail(
+		net.JoinHostPort(m.conf.Host, strconv.Itoa(m.conf.Port)),
+		auth,
+		m.conf.From,
+		m.conf.Recipients,
+		msg,
+	); err != nil {
+		return errors.Wrap(err, "could not send email")
+	}
+
+	return nil
+}
+
+// compose builds a MIME message of the given issue.
+func (m Mailer) compose(issue newspaper.Issue) ([]byte, error) {
+	var body bytes.Buffer
+
+	w := multipart.NewWriter(&body)
+
+	if err := writePart(w, "text/plain; charset=UTF-8", toPlainText(issue)); err != nil {
+		return nil, err
+	}
+
+	html, err := toHTML(issue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writePart(w, "text/html; charset=UTF-8", html); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+
+	headers := []string{
+		"From: " + m.conf.From,
+		"To: " + strings.Join(m.conf.Recipients, ", "),
+		"Subject: " + mime.QEncoding.Encode("UTF-8", getSubject(issue)),
+		"Date: " + newspaper.TimeNowFunc().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + w.Boundary(),
+	}
+
+	for _, h := range headers {
+		msg.WriteString(h + "\r\n")
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+func writePart(w *multipart.Writer, contentType, content string) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+
+	return qp.Close()
+}
+
+func getSubject(issue newspaper.Issue) string {
+	subject := "The Daily Bugle, " +
+		newspaper.TimeNowFunc().Format("Monday, 2 January 2006")
+
+	if len(issue) > 0 {
+		subject += ": " + issue[0].HeadlineText
+	}
+
+	return subject
+}
+
+// toPlainText renders the given issue as plain text. Line breaks are turned into
+// CRLF by the quoted-printable encoding.
+func toPlainText(issue newspaper.Issue) string {
+	var pages []string
+	for _, page := range issue {
+		pages = append(pages, render.PlainText(page))
+	}
+	return strings.Join(pages, "\n\n\n")
+}
+
+var htmlTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="UTF-8">
+<title>{{.Title}}</title>
+</head>
+<body style="margin:0;padding:24px;background:#f4f1ea;font-family:Georgia,serif;color:#222;">
+<div style="max-width:640px;margin:0 auto;background:#fff;padding:24px 32px;">
+<h1 style="font-size:32px;text-align:center;border-bottom:3px double #222;padding-bottom:12px;">{{.Title}}</h1>
+{{range .Pages}}
+<h2 style="font-size:22px;margin:24px 0 8px;">{{.Headline}}</h2>
+{{range .Content}}<p style="white-space:pre-wrap;line-height:1.5;">{{.}}</p>
+{{end}}
+<p style="font-style:italic;color:#777;font-size:13px;">By {{.Author}}</p>
+<hr style="border:0;border-top:1px solid #ddd;">
+{{end}}
+</div>
+</body>
+</html>
+`))
+
+// toHTML renders the given issue as an HTML document styled with inline styles,
+// since most email clients ignore style sheets.
+func toHTML(issue newspaper.Issue) (string, error) {
+	data := struct {
+		Title string
+		Pages []render.HTMLPage
+	}{
+		Title: "The Daily Bugle",
+		Pages: render.HTMLPages(issue),
+	}
+
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, data); err != nil {
+		return "", errors.Wrap(err, "could not render HTML")
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,257 @@
+package email
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+// smtpServer is a fake SMTP server that accepts a single message without
+// advertising any extensions, so that the client neither upgrades the connection
+// nor authenticates.
+type smtpServer struct {
+	listener net.Listener
+	done     chan struct{}
+
+	from       string
+	recipients []string
+	data       []byte
+	err        error
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+
+	s := &smtpServer{
+		listener: l,
+		done:     make(chan struct{}),
+	}
+
+	go s.serve()
+
+	return s
+}
+
+func (s *smtpServer) hostPort() (string, int) {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func (s *smtpServer) close() {
+	s.listener.Close()
+	<-s.done
+}
+
+func (s *smtpServer) serve() {
+	defer close(s.done)
+
+	conn, err := s.listener.Accept()
+	if err != nil {
+		s.err = err
+		return
+	}
+	defer conn.Close()
+
+	c := textproto.NewConn(conn)
+
+	if err := c.PrintfLine("220 localhost ESMTP"); err != nil {
+		s.err = err
+		return
+	}
+
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			s.err = err
+			return
+		}
+
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch cmd {
+		case "EHLO", "HELO":
+			err = c.PrintfLine("250 localhost")
+		case "MAIL":
+			s.from = strings.TrimSuffix(strings.TrimPrefix(line, "MAIL FROM:<"), ">")
+			err = c.PrintfLine("250 OK")
+		case "RCPT":
+			s.recipients = append(s.recipients,
+				strings.TrimSuffix(strings.TrimPrefix(line, "RCPT TO:<"), ">"),
+			)
+			err = c.PrintfLine("250 OK")
+		case "DATA":
+			if err = c.PrintfLine("354 Go ahead"); err != nil {
+				break
+			}
+			if s.data, err = c.ReadDotBytes(); err != nil {
+				break
+			}
+			err = c.PrintfLine("250 OK")
+		case "QUIT":
+			_ = c.PrintfLine("221 Bye")
+			return
+		default:
+			err = c.PrintfLine("502 Unsupported command")
+		}
+
+		if err != nil {
+			s.err = err
+			return
+		}
+	}
+}
+
+func TestMailer_Publish(t *testing.T) {
+	defer func(f func() time.Time) {
+		newspaper.TimeNowFunc = f
+	}(newspaper.TimeNowFunc)
+
+	newspaper.TimeNowFunc = func() time.Time {
+		return time.Date(2021, 2, 15, 9, 0, 0, 0, time.UTC)
+	}
+
+	srv := newSMTPServer(t)
+	host, port := srv.hostPort()
+
+	m := NewMailer(Config{
+		Host:       host,
+		Port:       port,
+		From:       "bugle@example.com",
+		Recipients: []string{"team@example.com", "boss@example.com"},
+	})
+
+	err := m.Publish(newspaper.Issue{
+		{
+			HeadlineEmojiName: "newspaper",
+			HeadlineText:      "Front page",
+			AuthorName:        "Peter <Parker>",
+			ContentElements: []slack.Block{
+				slack.NewSectionBlock(slack.NewTextBlockObject(
+					slack.MarkdownType, "Hello <https://example.com|world>", false, false,
+				), nil, nil),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	srv.close()
+
+	if srv.err != nil {
+		t.Fatalf("unexpected server error: %v", srv.err)
+	}
+
+	if srv.from != "bugle@example.com" {
+		t.Errorf("unexpected sender %q", srv.from)
+	}
+	if strings.Join(srv.recipients, ",") != "team@example.com,boss@example.com" {
+		t.Errorf("unexpected recipients %q", srv.recipients)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(srv.data))
+	if err != nil {
+		t.Fatalf("could not parse message: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("could not decode subject: %v", err)
+	}
+
+	headers := map[string]string{
+		"From":         "bugle@example.com",
+		"To":           "team@example.com, boss@example.com",
+		"Date":         "Mon, 15 Feb 2021 09:00:00 +0000",
+		"MIME-Version": "1.0",
+	}
+	for key, expected := range headers {
+		if actual := msg.Header.Get(key); actual != expected {
+			t.Errorf("expected header %s to be %q, got %q", key, expected, actual)
+		}
+	}
+	if expected := "The Daily Bugle, Monday, 15 February 2021: Front page"; subject != expected {
+		t.Errorf("expected subject %q, got %q", expected, subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("could not parse content type: %v", err)
+	}
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("expected multipart/alternative, got %s", mediaType)
+	}
+
+	parts := readParts(t, multipart.NewReader(msg.Body, params["boundary"]))
+
+	if len(parts) != 2 {
+		t.Fatalf("expected 2 parts, got %d", len(parts))
+	}
+
+	if parts[0].contentType != "text/plain; charset=UTF-8" {
+		t.Errorf("unexpected content type of the first part %q", parts[0].contentType)
+	}
+	if !strings.Contains(parts[0].body, "Front page") ||
+		!strings.Contains(parts[0].body, "By Peter <Parker>") {
+
+		t.Errorf("unexpected plain-text body %q", parts[0].body)
+	}
+
+	if parts[1].contentType != "text/html; charset=UTF-8" {
+		t.Errorf("unexpected content type of the second part %q", parts[1].contentType)
+	}
+	for _, s := range []string{
+		"📰 Front page",
+		`Hello <a href="https://example.com">world</a>`,
+		"By Peter &lt;Parker&gt;",
+	} {
+		if !strings.Contains(parts[1].body, s) {
+			t.Errorf("expected HTML body to contain %q, got %q", s, parts[1].body)
+		}
+	}
+}
+
+type part struct {
+	contentType string
+	body        string
+}
+
+// readParts reads all parts of the given multipart body. Quoted-printable parts
+// are decoded by the reader.
+func readParts(t *testing.T, r *multipart.Reader) []part {
+	var parts []part
+
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		if err != nil {
+			t.Fatalf("could not read part: %v", err)
+		}
+
+		body, err := ioutil.ReadAll(p)
+		if err != nil {
+			t.Fatalf("could not read part: %v", err)
+		}
+
+		parts = append(parts, part{
+			contentType: p.Header.Get("Content-Type"),
+			body:        string(body),
+		})
+	}
+}
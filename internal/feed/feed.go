@@ -0,0 +1,334 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html/template"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/archive"
+	"github.com/ztimes2/dailybugle/internal/atomicfile"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+	"github.com/ztimes2/dailybugle/internal/render"
+)
+
+const (
+	atomNamespace = "http://www.w3.org/2005/Atom"
+
+	defaultTitle      = "The Daily Bugle"
+	defaultMaxEntries = 30
+)
+
+// Feed provides functionality for publishing the newspaper's issues as entries of
+// an Atom feed and, optionally, an RSS 2.0 feed. The Atom feed's file also serves
+// as the storage of past entries, so no other state is kept.
+type Feed struct {
+	conf Config
+}
+
+// Config holds Feed's configuration.
+type Config struct {
+	// Title is the title of the feed.
+	Title string
+
+	// BaseURL is the absolute URL of the site serving the feed. Entries link to
+	// issue permalinks relative to it (e.g. https://bugle.example.com/2021/02/15/).
+	BaseURL string
+
+	// AtomPath is the path of the Atom feed's file.
+	AtomPath string
+
+	// RSSPath is the path of the RSS 2.0 feed's file. The RSS feed is not
+	// generated when empty.
+	RSSPath string
+
+	// MaxEntries is the number of the most recent issues kept in the feed.
+	MaxEntries int
+}
+
+// New initializes a new Feed.
+func New(conf Config) (Feed, error) {
+	u, err := url.Parse(conf.BaseURL)
+	if err != nil {
+		return Feed{}, errors.Wrap(err, "could not parse base URL")
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return Feed{}, errors.Errorf("base URL %q is not absolute", conf.BaseURL)
+	}
+
+	if conf.Title == "" {
+		conf.Title = defaultTitle
+	}
+	if conf.MaxEntries <= 0 {
+		conf.MaxEntries = defaultMaxEntries
+	}
+	conf.BaseURL = strings.TrimSuffix(conf.BaseURL, "/") + "/"
+
+	return Feed{
+		conf: conf,
+	}, nil
+}
+
+// Publish implements newspaper.Publisher interface and adds the given newspaper
+// issue to the feeds as a new entry. An issue published on a day that already has
+// an entry replaces it while keeping the entry's ID.
+func (f Feed) Publish(issue newspaper.Issue) error {
+	feed, err := f.readAtom()
+	if err != nil {
+		return errors.Wrap(err, "could not read Atom feed")
+	}
+
+	now := newspaper.TimeNowFunc()
+
+	e, err := f.toEntry(issue, now)
+	if err != nil {
+		return errors.Wrap(err, "could not render entry")
+	}
+
+	feed.Entries = upsertEntry(feed.Entries, e, f.conf.MaxEntries)
+	feed.Updated = now.Format(time.RFC3339)
+
+	if err := writeXML(f.conf.AtomPath, feed); err != nil {
+		return errors.Wrap(err, "could not write Atom feed")
+	}
+
+	if f.conf.RSSPath != "" {
+		if err := writeXML(f.conf.RSSPath, f.toRSS(feed)); err != nil {
+			return errors.Wrap(err, "could not write RSS feed")
+		}
+	}
+
+	return nil
+}
+
+// IsIdempotent implements newspaper.IdempotentPublisher interface. Publishing an
+// issue again replaces the entry of the same day.
+func (f Feed) IsIdempotent() bool {
+	return true
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Published string      `xml:"published"`
+	Updated   string      `xml:"updated"`
+	Link      atomLink    `xml:"link"`
+	Summary   string      `xml:"summary"`
+	Content   atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// readAtom reads the Atom feed's file or returns an empty feed if it does not
+// exist yet.
+func (f Feed) readAtom() (atomFeed, error) {
+	feed := atomFeed{
+		XMLNS: atomNamespace,
+		ID:    f.conf.BaseURL,
+		Title: f.conf.Title,
+		Links: []atomLink{
+			{Href: f.conf.BaseURL},
+		},
+		Author: atomAuthor{
+			Name: f.conf.Title,
+		},
+	}
+
+	data, err := ioutil.ReadFile(f.conf.AtomPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return feed, nil
+		}
+		return atomFeed{}, err
+	}
+
+	var stored atomFeed
+	if err := xml.Unmarshal(data, &stored); err != nil {
+		return atomFeed{}, err
+	}
+
+	feed.Entries = stored.Entries
+
+	return feed, nil
+}
+
+// toEntry turns the given issue published at the given time into an entry. The
+// entry's ID is derived from the issue's permalink so that it stays the same
+// whenever the issue is published again.
+func (f Feed) toEntry(issue newspaper.Issue, t time.Time) (atomEntry, error) {
+	link := f.conf.BaseURL + archive.Permalink(t)
+
+	var headlines []string
+	for _, page := range issue {
+		headlines = append(headlines, page.HeadlineText)
+	}
+
+	content, err := toHTML(issue)
+	if err != nil {
+		return atomEntry{}, err
+	}
+
+	return atomEntry{
+		ID:        link,
+		Title:     f.conf.Title + ", " + t.Format("Monday, 2 January 2006"),
+		Published: t.Format(time.RFC3339),
+		Updated:   t.Format(time.RFC3339),
+		Link:      atomLink{Href: link, Rel: "alternate", Type: "text/html"},
+		Summary:   strings.Join(headlines, " · "),
+		Content: atomContent{
+			Type: "html",
+			Body: content,
+		},
+	}, nil
+}
+
+// upsertEntry adds the given entry to the list replacing an entry with the same
+// ID, sorts the list from the newest to the oldest entry and keeps at most the
+// given number of entries.
+func upsertEntry(entries []atomEntry, e atomEntry, max int) []atomEntry {
+	replaced := false
+
+	for i := range entries {
+		if entries[i].ID == e.ID {
+			// Keeps the time of the very first publication.
+			e.Published = entries[i].Published
+			entries[i] = e
+			replaced = true
+			break
+		}
+	}
+
+	if !replaced {
+		entries = append(entries, e)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Published > entries[j].Published
+	})
+
+	if len(entries) > max {
+		entries = entries[:max]
+	}
+
+	return entries
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// toRSS converts the given Atom feed to an RSS 2.0 feed.
+func (f Feed) toRSS(feed atomFeed) rssFeed {
+	rss := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         feed.Title,
+			Link:          f.conf.BaseURL,
+			Description:   "Past issues of " + feed.Title,
+			LastBuildDate: toRFC1123(feed.Updated),
+		},
+	}
+
+	for _, e := range feed.Entries {
+		rss.Channel.Items = append(rss.Channel.Items, rssItem{
+			Title: e.Title,
+			Link:  e.Link.Href,
+			GUID: rssGUID{
+				IsPermaLink: true,
+				Value:       e.ID,
+			},
+			PubDate:     toRFC1123(e.Published),
+			Description: e.Content.Body,
+		})
+	}
+
+	return rss
+}
+
+func toRFC1123(rfc3339 string) string {
+	t, err := time.Parse(time.RFC3339, rfc3339)
+	if err != nil {
+		return ""
+	}
+	return t.Format(time.RFC1123Z)
+}
+
+// writeXML replaces the file of the given path atomically, since the Atom feed's
+// file is the only storage of past entries and must not be left half-written.
+func writeXML(path string, v interface{}) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(path, append([]byte(xml.Header), data...), 0644)
+}
+
+var contentTemplate = template.Must(template.New("content").Parse(
+	`{{range .}}<h2>{{.Headline}}</h2>
+{{range .Content}}<p>{{.}}</p>
+{{end}}<p><em>By {{.Author}}</em></p>
+{{end}}`))
+
+// toHTML renders pages of the given issue as an HTML fragment.
+func toHTML(issue newspaper.Issue) (string, error) {
+	var b bytes.Buffer
+	if err := contentTemplate.Execute(&b, render.HTMLPages(issue)); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,326 @@
+package github
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/junit"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+// Actions provides communication with GitHub Actions REST API.
+type Actions struct {
+	api
+	repositories []string
+	workflow     string
+	testReports  string
+}
+
+// ActionsConfig holds Actions' configuration.
+type ActionsConfig struct {
+	// BaseURL defaults to GitHub's public API. GitHub Enterprise Server's API is
+	// served under /api/v3 of its host.
+	BaseURL string
+	Token   string
+
+	// Repositories are full names of repositories (e.g. ztimes2/dailybugle).
+	Repositories []string
+
+	// Workflow limits runs to the workflow with the given file name (e.g. ci.yml).
+	// Runs of all active workflows are fetched when empty.
+	Workflow string
+
+	// TestReportsArtifact is the name of workflow artifacts containing JUnit XML
+	// reports.
+	TestReportsArtifact string
+}
+
+// NewActions initializes a new Actions.
+func NewActions(c *http.Client, conf ActionsConfig) Actions {
+	return Actions{
+		api:          newAPI(c, conf.BaseURL, conf.Token),
+		repositories: conf.Repositories,
+		workflow:     conf.Workflow,
+		testReports:  conf.TestReportsArtifact,
+	}
+}
+
+type repository struct {
+	FullName      string `json:"full_name"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+type workflowsResponse struct {
+	Workflows []struct {
+		ID    int64  `json:"id"`
+		State string `json:"state"`
+	} `json:"workflows"`
+}
+
+type workflowRunsResponse struct {
+	WorkflowRuns []struct {
+		Name         string    `json:"name"`
+		RunNumber    int       `json:"run_number"`
+		HTMLURL      string    `json:"html_url"`
+		Status       string    `json:"status"`
+		Conclusion   *string   `json:"conclusion"`
+		CreatedAt    time.Time `json:"created_at"`
+		UpdatedAt    time.Time `json:"updated_at"`
+		RunStartedAt time.Time `json:"run_started_at"`
+	} `json:"workflow_runs"`
+}
+
+// GetDefaultBranchBuilds implements newspaper.BuildSource interface and fetches
+// the most recent workflow runs of default branches of the repositories, at most
+// the given number per workflow. Runs of different workflows are reported as
+// separate pipelines.
+func (a Actions) GetDefaultBranchBuilds(limit int) ([]newspaper.Build, error) {
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	var builds []newspaper.Build
+
+	for _, name := range a.repositories {
+		var repo repository
+		if err := a.get("/repos/"+name, nil, &repo); err != nil {
+			return nil, errors.Wrapf(err, "could not fetch repository %s", name)
+		}
+
+		workflows, err := a.getWorkflows(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not fetch workflows of %s", name)
+		}
+
+		for _, workflow := range workflows {
+			b, err := a.getWorkflowBuilds(repo, workflow, limit)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not fetch workflow runs of %s", name)
+			}
+			builds = append(builds, b...)
+		}
+	}
+
+	return builds, nil
+}
+
+// getWorkflows returns IDs or file names of the workflows of the given
+// repository whose runs are reported. Runs are fetched per workflow, so that
+// busy workflows do not crowd out runs of the others.
+func (a Actions) getWorkflows(repo string) ([]string, error) {
+	if a.workflow != "" {
+		return []string{a.workflow}, nil
+	}
+
+	var workflows []string
+
+	for page := 1; ; page++ {
+		var resp workflowsResponse
+		if err := a.get("/repos/"+repo+"/actions/workflows", url.Values{
+			"per_page": {strconv.Itoa(maxPageSize)},
+			"page":     {strconv.Itoa(page)},
+		}, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, w := range resp.Workflows {
+			// Disabled workflows no longer run, so their last runs are stale.
+			if w.State == "active" {
+				workflows = append(workflows, strconv.FormatInt(w.ID, 10))
+			}
+		}
+
+		if len(resp.Workflows) < maxPageSize {
+			return workflows, nil
+		}
+	}
+}
+
+// getWorkflowBuilds returns the given number of the most recent runs of the
+// given workflow on the default branch of the given repository.
+func (a Actions) getWorkflowBuilds(repo repository, workflow string, limit int,
+) ([]newspaper.Build, error) {
+
+	var resp workflowRunsResponse
+	if err := a.get(
+		"/repos/"+repo.FullName+"/actions/workflows/"+workflow+"/runs",
+		url.Values{
+			"branch":   {repo.DefaultBranch},
+			"per_page": {strconv.Itoa(limit)},
+		},
+		&resp,
+	); err != nil {
+		return nil, err
+	}
+
+	var builds []newspaper.Build
+
+	for _, r := range resp.WorkflowRuns {
+		b := newspaper.Build{
+			Repository: repo.FullName,
+			Branch:     repo.DefaultBranch,
+			Workflow:   r.Name,
+			ID:         "#" + strconv.Itoa(r.RunNumber),
+			URL:        r.HTMLURL,
+			StartedAt:  r.RunStartedAt,
+		}
+
+		if b.StartedAt.IsZero() {
+			b.StartedAt = r.CreatedAt
+		}
+
+		if r.Status == "completed" {
+			// Completed runs are no longer updated.
+			b.FinishedAt = r.UpdatedAt
+			b.Status = toBuildStatus(r.Conclusion)
+		}
+
+		builds = append(builds, b)
+	}
+
+	return builds, nil
+}
+
+type artifactsResponse struct {
+	Artifacts []struct {
+		Name               string    `json:"name"`
+		Expired            bool      `json:"expired"`
+		CreatedAt          time.Time `json:"created_at"`
+		ArchiveDownloadURL string    `json:"archive_download_url"`
+		WorkflowRun        struct {
+			HeadSHA string `json:"head_sha"`
+		} `json:"workflow_run"`
+	} `json:"artifacts"`
+}
+
+// GetTestResultsSince implements newspaper.TestResultSource interface and reads
+// JUnit XML reports from workflow artifacts of the repositories that were
+// created since the given time.
+func (a Actions) GetTestResultsSince(t time.Time) ([]newspaper.TestResult, error) {
+	var results []newspaper.TestResult
+
+	for _, name := range a.repositories {
+		for page := 1; ; page++ {
+			var resp artifactsResponse
+			if err := a.get("/repos/"+name+"/actions/artifacts", url.Values{
+				"name":     {a.testReports},
+				"per_page": {strconv.Itoa(maxPageSize)},
+				"page":     {strconv.Itoa(page)},
+			}, &resp); err != nil {
+				return nil, errors.Wrapf(err, "could not fetch artifacts of %s", name)
+			}
+
+			done := len(resp.Artifacts) < maxPageSize
+
+			// Artifacts are listed from the newest to the oldest one.
+			for _, artifact := range resp.Artifacts {
+				if artifact.CreatedAt.Before(t) {
+					done = true
+					break
+				}
+
+				if artifact.Name != a.testReports || artifact.Expired {
+					continue
+				}
+
+				r, err := a.readTestReports(
+					artifact.ArchiveDownloadURL,
+					artifact.WorkflowRun.HeadSHA,
+					artifact.CreatedAt,
+				)
+				if err != nil {
+					return nil, errors.Wrapf(err, "could not read test reports of %s", name)
+				}
+
+				results = append(results, r...)
+			}
+
+			if done {
+				break
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// readTestReports downloads the zip archive of an artifact and parses all the
+// JUnit XML reports in it.
+func (a Actions) readTestReports(archiveURL, commit string, createdAt time.Time,
+) ([]newspaper.TestResult, error) {
+
+	req, err := http.NewRequest(http.MethodGet, archiveURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not prepare request")
+	}
+
+	a.authorize(req)
+
+	// GitHub redirects to a short-lived URL of the archive on another host, so the
+	// authorization is not sent any further.
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not send request")
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read response")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, errors.Errorf("unexpected response %s: %s", resp.Status, b)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open archive")
+	}
+
+	var results []newspaper.TestResult
+
+	for _, f := range archive.File {
+		if !strings.EqualFold(path.Ext(f.Name), ".xml") {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not open %s", f.Name)
+		}
+
+		r, err := junit.Parse(rc, commit, createdAt)
+		rc.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse %s", f.Name)
+		}
+
+		results = append(results, r...)
+	}
+
+	return results, nil
+}
+
+func toBuildStatus(conclusion *string) newspaper.BuildStatus {
+	if conclusion == nil {
+		return newspaper.BuildStatusRunning
+	}
+
+	switch *conclusion {
+	case "success":
+		return newspaper.BuildStatusPassed
+	case "failure", "timed_out", "startup_failure":
+		return newspaper.BuildStatusFailed
+	default:
+		return newspaper.BuildStatusCanceled
+	}
+}
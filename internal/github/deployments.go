@@ -0,0 +1,162 @@
+package github
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+// shortSHALength is the length commit SHAs are abbreviated to.
+const shortSHALength = 7
+
+// Deployments provides communication with GitHub Deployments REST API.
+type Deployments struct {
+	api
+	repositories []string
+	environments []string
+}
+
+// DeploymentsConfig holds Deployments' configuration.
+type DeploymentsConfig struct {
+	// BaseURL defaults to GitHub's public API.
+	BaseURL string
+	Token   string
+
+	// Repositories are full names of repositories (e.g. ztimes2/dailybugle).
+	Repositories []string
+
+	// Environments limits deployments to the given environments. Deployments to
+	// all environments are fetched when empty.
+	Environments []string
+}
+
+// NewDeployments initializes a new Deployments.
+func NewDeployments(c *http.Client, conf DeploymentsConfig) Deployments {
+	return Deployments{
+		api:          newAPI(c, conf.BaseURL, conf.Token),
+		repositories: conf.Repositories,
+		environments: conf.Environments,
+	}
+}
+
+type deployment struct {
+	ID          int       `json:"id"`
+	SHA         string    `json:"sha"`
+	Ref         string    `json:"ref"`
+	Environment string    `json:"environment"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	Creator     struct {
+		Login string `json:"login"`
+	} `json:"creator"`
+}
+
+type deploymentStatus struct {
+	State     string    `json:"state"`
+	LogURL    string    `json:"log_url"`
+	TargetURL string    `json:"target_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GetDeploymentsSince implements newspaper.DeploymentSource interface and fetches
+// successful deployments of the repositories created since the given time.
+// Services are named after the repositories.
+func (d Deployments) GetDeploymentsSince(t time.Time) ([]newspaper.Deployment, error) {
+	environments := d.environments
+	if len(environments) == 0 {
+		environments = []string{""}
+	}
+
+	var deployments []newspaper.Deployment
+
+	for _, name := range d.repositories {
+		for _, env := range environments {
+			dep, err := d.getDeployments(name, env, t)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not fetch deployments of %s", name)
+			}
+			deployments = append(deployments, dep...)
+		}
+	}
+
+	return deployments, nil
+}
+
+func (d Deployments) getDeployments(repo, env string, t time.Time,
+) ([]newspaper.Deployment, error) {
+
+	query := url.Values{
+		"per_page": {strconv.Itoa(maxPageSize)},
+	}
+	if env != "" {
+		query.Set("environment", env)
+	}
+
+	var deployments []newspaper.Deployment
+
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+
+		var resp []deployment
+		if err := d.get("/repos/"+repo+"/deployments", query, &resp); err != nil {
+			return nil, err
+		}
+
+		done := len(resp) < maxPageSize
+
+		// Deployments are listed from the newest to the oldest one.
+		for _, dep := range resp {
+			if dep.CreatedAt.Before(t) {
+				done = true
+				break
+			}
+
+			var statuses []deploymentStatus
+			if err := d.get(
+				"/repos/"+repo+"/deployments/"+strconv.Itoa(dep.ID)+"/statuses",
+				url.Values{"per_page": {"1"}},
+				&statuses,
+			); err != nil {
+				return nil, errors.Wrapf(err, "could not fetch statuses of deployment %d", dep.ID)
+			}
+
+			// Statuses are listed from the newest to the oldest one. Deployments
+			// that were superseded by later ones become inactive.
+			if len(statuses) == 0 ||
+				(statuses[0].State != "success" && statuses[0].State != "inactive") {
+				continue
+			}
+
+			version := dep.Ref
+			if version == dep.SHA && len(version) > shortSHALength {
+				version = version[:shortSHALength]
+			}
+
+			link := statuses[0].LogURL
+			if link == "" {
+				link = statuses[0].TargetURL
+			}
+
+			deployments = append(deployments, newspaper.Deployment{
+				Service:     path.Base(repo),
+				Environment: dep.Environment,
+				Version:     version,
+				Deployer:    dep.Creator.Login,
+				URL:         link,
+				TicketIDs:   newspaper.ParseTicketIDs(dep.Ref + " " + dep.Description),
+				DeployedAt:  dep.CreatedAt,
+			})
+		}
+
+		if done {
+			break
+		}
+	}
+
+	return deployments, nil
+}
@@ -0,0 +1,58 @@
+package github
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/httpjson"
+)
+
+const defaultBaseURL = "https://api.github.com"
+
+// maxPageSize is the maximum number of items GitHub returns per page.
+const maxPageSize = 100
+
+// api provides communication with GitHub REST API shared by the package's
+// services.
+type api struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+// newAPI initializes a new api. The base URL defaults to GitHub's public API.
+func newAPI(c *http.Client, baseURL, token string) api {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return api{
+		client:  c,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+	}
+}
+
+// authorize sets the authorization header of the given request if a token is
+// configured.
+func (a api) authorize(req *http.Request) {
+	if a.token != "" {
+		req.Header.Set("Authorization", "token "+a.token)
+	}
+}
+
+// get sends a GET request to the given path of the API and decodes the response
+// into v.
+func (a api) get(path string, query url.Values, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, a.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return errors.Wrap(err, "could not prepare request")
+	}
+
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	a.authorize(req)
+
+	return httpjson.Do(a.client, req, v)
+}
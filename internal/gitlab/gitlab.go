@@ -0,0 +1,143 @@
+package gitlab
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/httpjson"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+const defaultBaseURL = "https://gitlab.com"
+
+// maxPageSize is the maximum number of items GitLab returns per page.
+const maxPageSize = 100
+
+// GitLab provides communication with GitLab REST API.
+type GitLab struct {
+	client   *http.Client
+	baseURL  string
+	token    string
+	projects []string
+}
+
+// Config holds GitLab's configuration.
+type Config struct {
+	// BaseURL defaults to gitlab.com.
+	BaseURL string
+	Token   string
+
+	// Projects are paths of projects including their namespaces
+	// (e.g. ztimes2/dailybugle).
+	Projects []string
+}
+
+// New initializes a new GitLab.
+func New(c *http.Client, conf Config) GitLab {
+	if conf.BaseURL == "" {
+		conf.BaseURL = defaultBaseURL
+	}
+
+	return GitLab{
+		client:   c,
+		baseURL:  strings.TrimSuffix(conf.BaseURL, "/"),
+		token:    conf.Token,
+		projects: conf.Projects,
+	}
+}
+
+type project struct {
+	ID                int    `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	DefaultBranch     string `json:"default_branch"`
+}
+
+type pipeline struct {
+	ID        int       `json:"id"`
+	Status    string    `json:"status"`
+	WebURL    string    `json:"web_url"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GetDefaultBranchBuilds implements newspaper.BuildSource interface and fetches
+// the most recent pipelines of default branches of the projects.
+func (g GitLab) GetDefaultBranchBuilds(limit int) ([]newspaper.Build, error) {
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	var builds []newspaper.Build
+
+	for _, path := range g.projects {
+		var p project
+		if err := g.get("/projects/"+url.PathEscape(path), nil, &p); err != nil {
+			return nil, errors.Wrapf(err, "could not fetch project %s", path)
+		}
+
+		var pipelines []pipeline
+		if err := g.get("/projects/"+strconv.Itoa(p.ID)+"/pipelines", url.Values{
+			"ref":      {p.DefaultBranch},
+			"per_page": {strconv.Itoa(limit)},
+			"order_by": {"id"},
+			"sort":     {"desc"},
+		}, &pipelines); err != nil {
+			return nil, errors.Wrapf(err, "could not fetch pipelines of %s", path)
+		}
+
+		for _, pl := range pipelines {
+			b := newspaper.Build{
+				Repository: p.PathWithNamespace,
+				Branch:     p.DefaultBranch,
+				ID:         "#" + strconv.Itoa(pl.ID),
+				URL:        pl.WebURL,
+				Status:     toBuildStatus(pl.Status),
+				// Listed pipelines come without their start and finish times, so
+				// the times of their creation and last update are used instead.
+				StartedAt: pl.CreatedAt,
+			}
+
+			if b.Status != newspaper.BuildStatusRunning {
+				b.FinishedAt = pl.UpdatedAt
+			}
+
+			builds = append(builds, b)
+		}
+	}
+
+	return builds, nil
+}
+
+func toBuildStatus(status string) newspaper.BuildStatus {
+	switch status {
+	case "success":
+		return newspaper.BuildStatusPassed
+	case "failed":
+		return newspaper.BuildStatusFailed
+	case "canceled", "skipped":
+		return newspaper.BuildStatusCanceled
+	default:
+		return newspaper.BuildStatusRunning
+	}
+}
+
+// get sends a GET request to the given path of the API and decodes the response
+// into v.
+func (g GitLab) get(path string, query url.Values, v interface{}) error {
+	req, err := http.NewRequest(
+		http.MethodGet, g.baseURL+"/api/v4"+path+"?"+query.Encode(), nil,
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not prepare request")
+	}
+
+	if g.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", g.token)
+	}
+
+	return httpjson.Do(g.client, req, v)
+}
@@ -0,0 +1,182 @@
+package gitlog
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+// defaultTagPattern matches semantic version tags (e.g. v1.4.2 or 1.4.2).
+const defaultTagPattern = `^v?(\d+)\.(\d+)\.(\d+)$`
+
+// Repository provides functionality for reading releases from a local git
+// repository. Releases are tags matching the tag pattern, and the latest one is
+// the tag of the highest version. The next release is made of commits reachable
+// from HEAD.
+type Repository struct {
+	path       string
+	tagPattern *regexp.Regexp
+}
+
+// New initializes a new Repository. Tags are matched against the given regular
+// expression, which defaults to semantic versions. Numbers captured by its
+// groups are compared to find the latest release.
+func New(path, tagPattern string) (Repository, error) {
+	if tagPattern == "" {
+		tagPattern = defaultTagPattern
+	}
+
+	re, err := regexp.Compile(tagPattern)
+	if err != nil {
+		return Repository{}, errors.Wrap(err, "could not compile tag pattern")
+	}
+
+	return Repository{
+		path:       path,
+		tagPattern: re,
+	}, nil
+}
+
+type release struct {
+	tag     string
+	version []int
+	commit  plumbing.Hash
+}
+
+// GetNextRelease implements newspaper.ReleaseHistory interface and reads commits
+// reachable from HEAD but not from the latest release's tag. Merge commits are
+// left out.
+func (r Repository) GetNextRelease() (newspaper.Release, error) {
+	repo, err := git.PlainOpenWithOptions(r.path, &git.PlainOpenOptions{
+		DetectDotGit: true,
+	})
+	if err != nil {
+		return newspaper.Release{}, errors.Wrap(err, "could not open repository")
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return newspaper.Release{}, errors.Wrap(err, "could not resolve HEAD")
+	}
+
+	releases, err := r.getReleases(repo)
+	if err != nil {
+		return newspaper.Release{}, err
+	}
+
+	var rel newspaper.Release
+
+	released := make(map[plumbing.Hash]bool)
+
+	if len(releases) > 0 {
+		rel.PreviousTag = releases[0].tag
+
+		if err := walk(repo, releases[0].commit, nil, func(c *object.Commit) error {
+			released[c.Hash] = true
+			return nil
+		}); err != nil {
+			return newspaper.Release{}, errors.Wrap(err, "could not read history")
+		}
+	}
+
+	if err := walk(repo, head.Hash(), released, func(c *object.Commit) error {
+		if c.NumParents() > 1 {
+			return nil
+		}
+
+		rel.Commits = append(rel.Commits, newspaper.Commit{
+			Hash:        c.Hash.String(),
+			Message:     c.Message,
+			AuthorName:  c.Author.Name,
+			CommittedAt: c.Committer.When,
+		})
+		return nil
+	}); err != nil {
+		return newspaper.Release{}, errors.Wrap(err, "could not read history")
+	}
+
+	sort.SliceStable(rel.Commits, func(i, j int) bool {
+		return rel.Commits[i].CommittedAt.After(rel.Commits[j].CommittedAt)
+	})
+
+	return rel, nil
+}
+
+// getReleases returns releases of the repository sorted from the highest to the
+// lowest version.
+func (r Repository) getReleases(repo *git.Repository) ([]release, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list tags")
+	}
+
+	var releases []release
+
+	if err := tags.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+
+		m := r.tagPattern.FindStringSubmatch(name)
+		if m == nil {
+			return nil
+		}
+
+		rel := release{
+			tag:    name,
+			commit: ref.Hash(),
+		}
+
+		for _, group := range m[1:] {
+			n, _ := strconv.Atoi(group)
+			rel.version = append(rel.version, n)
+		}
+
+		// Annotated tags point to tag objects rather than to commits.
+		if t, err := repo.TagObject(ref.Hash()); err == nil {
+			c, err := t.Commit()
+			if err != nil {
+				return errors.Wrapf(err, "could not resolve tag %s", name)
+			}
+			rel.commit = c.Hash
+		}
+
+		releases = append(releases, rel)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		a, b := releases[i].version, releases[j].version
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] > b[k]
+			}
+		}
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return strings.Compare(releases[i].tag, releases[j].tag) > 0
+	})
+
+	return releases, nil
+}
+
+// walk calls fn for every commit reachable from the given one skipping the
+// commits in the given set along with their ancestors.
+func walk(repo *git.Repository, from plumbing.Hash, skip map[plumbing.Hash]bool,
+	fn func(*object.Commit) error) error {
+
+	c, err := repo.CommitObject(from)
+	if err != nil {
+		return err
+	}
+
+	return object.NewCommitPreorderIter(c, skip, nil).ForEach(fn)
+}
@@ -0,0 +1,160 @@
+package google
+
+import (
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/ztimes2/dailybugle/internal/httpjson"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+	"github.com/ztimes2/dailybugle/internal/render"
+)
+
+// ChatSpace provides communication with a certain Google Chat space through its
+// incoming webhook.
+type ChatSpace struct {
+	client      *http.Client
+	webhookURL  string
+	jiraBaseURL string
+}
+
+// NewChatSpace initializes a new ChatSpace. Links pointing to the given Jira's
+// base URL are rendered as buttons.
+func NewChatSpace(c *http.Client, webhookURL, jiraBaseURL string) ChatSpace {
+	return ChatSpace{
+		client:      c,
+		webhookURL:  webhookURL,
+		jiraBaseURL: jiraBaseURL,
+	}
+}
+
+// Publish implements newspaper.Publisher interface and publishes the given
+// newspaper issue to the Google Chat space as a card with one section per page.
+func (c ChatSpace) Publish(issue newspaper.Issue) error {
+	return httpjson.Post(c.client, c.webhookURL, chatMessage{
+		CardsV2: []chatCardWithID{
+			{
+				CardID: "dailybugle",
+				Card:   c.toCard(issue),
+			},
+		},
+	})
+}
+
+type chatMessage struct {
+	CardsV2 []chatCardWithID `json:"cardsV2"`
+}
+
+type chatCardWithID struct {
+	CardID string   `json:"cardId"`
+	Card   chatCard `json:"card"`
+}
+
+type chatCard struct {
+	Header   chatCardHeader `json:"header"`
+	Sections []chatSection  `json:"sections"`
+}
+
+type chatCardHeader struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle"`
+}
+
+type chatSection struct {
+	Header  string       `json:"header"`
+	Widgets []chatWidget `json:"widgets"`
+}
+
+type chatWidget struct {
+	TextParagraph *chatTextParagraph `json:"textParagraph,omitempty"`
+	ButtonList    *chatButtonList    `json:"buttonList,omitempty"`
+}
+
+type chatTextParagraph struct {
+	Text string `json:"text"`
+}
+
+type chatButtonList struct {
+	Buttons []chatButton `json:"buttons"`
+}
+
+type chatButton struct {
+	Text    string      `json:"text"`
+	OnClick chatOnClick `json:"onClick"`
+}
+
+type chatOnClick struct {
+	OpenLink chatOpenLink `json:"openLink"`
+}
+
+type chatOpenLink struct {
+	URL string `json:"url"`
+}
+
+// toCard turns the given newspaper issue into a card where each page is rendered
+// as a separate section. Links to Jira found in a page are additionally rendered
+// as buttons at the end of its section.
+func (c ChatSpace) toCard(issue newspaper.Issue) chatCard {
+	card := chatCard{
+		Header: chatCardHeader{
+			Title:    "The Daily Bugle",
+			Subtitle: newspaper.TimeNowFunc().Format("Monday, 2 January 2006"),
+		},
+	}
+
+	for _, page := range issue {
+		s := chatSection{
+			Header: render.Headline(page),
+		}
+
+		var buttons []chatButton
+
+		for _, text := range page.ContentTexts() {
+			s.Widgets = append(s.Widgets, chatWidget{
+				TextParagraph: &chatTextParagraph{
+					Text: toChatHTML(text),
+				},
+			})
+
+			for _, l := range mrkdwn.Links(text) {
+				if c.jiraBaseURL == "" || !strings.HasPrefix(l.URL, c.jiraBaseURL) {
+					continue
+				}
+
+				buttons = append(buttons, chatButton{
+					Text: l.Text,
+					OnClick: chatOnClick{
+						OpenLink: chatOpenLink{URL: l.URL},
+					},
+				})
+			}
+		}
+
+		if len(buttons) > 0 {
+			s.Widgets = append(s.Widgets, chatWidget{
+				ButtonList: &chatButtonList{Buttons: buttons},
+			})
+		}
+
+		s.Widgets = append(s.Widgets, chatWidget{
+			TextParagraph: &chatTextParagraph{
+				Text: "<i>By " + html.EscapeString(page.AuthorName) + "</i>",
+			},
+		})
+
+		card.Sections = append(card.Sections, s)
+	}
+
+	return card
+}
+
+// toChatHTML converts the given string from Slack's mrkdwn format to the subset
+// of HTML supported by Google Chat's text paragraphs.
+func toChatHTML(s string) string {
+	return strings.NewReplacer(
+		"<strong>", "<b>", "</strong>", "</b>",
+		"<em>", "<i>", "</em>", "</i>",
+		"<del>", "<s>", "</del>", "</s>",
+	).Replace(mrkdwn.HTML(s))
+}
@@ -0,0 +1,85 @@
+// Package httpjson provides exchanging of JSON documents with HTTP APIs.
+package httpjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// ResponseError represents a response with a status code other than 2xx.
+type ResponseError struct {
+	Status     string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Error implements error interface.
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("unexpected response %s: %s", e.Status, e.Body)
+}
+
+// NewRequest prepares a request with the given method and URL carrying v encoded
+// as JSON.
+func NewRequest(method, url string, v interface{}) (*http.Request, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode request")
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not prepare request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+// Do sends the given request and decodes the body of a successful response into
+// v unless v is nil. Responses with a status code other than 2xx are returned as
+// *ResponseError.
+func Do(c *http.Client, req *http.Request, v interface{}) error {
+	resp, err := c.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "could not send request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := ioutil.ReadAll(resp.Body)
+		return &ResponseError{
+			Status:     resp.Status,
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       b,
+		}
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return errors.Wrap(err, "could not decode response")
+	}
+
+	return nil
+}
+
+// Post sends v encoded as JSON to the given URL in a POST request and ignores the
+// body of a successful response.
+func Post(c *http.Client, url string, v interface{}) error {
+	req, err := NewRequest(http.MethodPost, url, v)
+	if err != nil {
+		return err
+	}
+
+	return Do(c, req, nil)
+}
@@ -0,0 +1,450 @@
+package ics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+// Schedule provides access to an on-call schedule exported as an iCalendar feed,
+// which most on-call tools (e.g. Opsgenie, PagerDuty) are able to provide. Each
+// event of the feed is treated as a shift of the person named in its summary.
+// Daily and weekly recurring events are expanded into a shift per occurrence,
+// while feeds with other recurring events are rejected.
+type Schedule struct {
+	client           *http.Client
+	url              string
+	escalationPolicy string
+	level            int
+}
+
+// NewSchedule initializes a new Schedule. Shifts of the schedule are reported for
+// the given escalation policy and level.
+func NewSchedule(c *http.Client, url, escalationPolicy string, level int) Schedule {
+	return Schedule{
+		client:           c,
+		url:              url,
+		escalationPolicy: escalationPolicy,
+		level:            level,
+	}
+}
+
+// GetOnCallShiftsByDay implements newspaper.OnCallSchedule interface and returns
+// shifts of the schedule overlapping the given day.
+func (s Schedule) GetOnCallShiftsByDay(t time.Time) ([]newspaper.OnCallShift, error) {
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch calendar")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, errors.Errorf("unexpected response %s", resp.Status)
+	}
+
+	events, err := parseEvents(resp.Body, t.Location())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse calendar")
+	}
+
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	end := start.AddDate(0, 0, 1)
+
+	events, err = expandEvents(events, start, end)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not expand recurring events")
+	}
+
+	var shifts []newspaper.OnCallShift
+
+	for _, e := range events {
+		shifts = append(shifts, newspaper.OnCallShift{
+			EscalationPolicy: s.escalationPolicy,
+			Level:            s.level,
+			PersonName:       e.summary,
+			StartsAt:         e.start,
+			EndsAt:           e.end,
+		})
+	}
+
+	return shifts, nil
+}
+
+type event struct {
+	uid     string
+	summary string
+	start   time.Time
+	end     time.Time
+
+	// rule is the recurrence rule of a recurring event, and exceptions are
+	// start times of its occurrences that are excluded from it.
+	rule       string
+	exceptions []time.Time
+
+	// recurrenceID is the start time of the occurrence of the recurring event
+	// of the same UID that the event replaces.
+	recurrenceID time.Time
+}
+
+// parseEvents parses VEVENT components of the given iCalendar data. Only the
+// properties needed for on-call shifts are supported. Recurring events are
+// returned as they are, so they have to be expanded with expandEvents. Times
+// without a time zone are interpreted in the given location.
+func parseEvents(r io.Reader, loc *time.Location) ([]event, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events  []event
+		current *event
+	)
+
+	for _, line := range lines {
+		name, params, value := parseContentLine(line)
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			current = &event{}
+		case name == "END" && value == "VEVENT":
+			if current != nil && !current.start.IsZero() {
+				if current.end.IsZero() {
+					current.end = current.start.AddDate(0, 0, 1)
+				}
+				events = append(events, *current)
+			}
+			current = nil
+		case current == nil:
+			continue
+		case name == "UID":
+			current.uid = value
+		case name == "RRULE":
+			current.rule = value
+		case name == "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				t, err := parseDateTime(v, params, loc)
+				if err != nil {
+					return nil, err
+				}
+				current.exceptions = append(current.exceptions, t)
+			}
+		case name == "RECURRENCE-ID":
+			current.recurrenceID, err = parseDateTime(value, params, loc)
+			if err != nil {
+				return nil, err
+			}
+		case name == "SUMMARY":
+			current.summary = unescapeText(value)
+		case name == "DTSTART":
+			current.start, err = parseDateTime(value, params, loc)
+			if err != nil {
+				return nil, err
+			}
+		case name == "DTEND":
+			current.end, err = parseDateTime(value, params, loc)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// expandEvents returns events overlapping the period between the given times
+// with recurring events expanded into their occurrences. Occurrences replaced by
+// other events of the same UID are left out.
+func expandEvents(events []event, from, to time.Time) ([]event, error) {
+	replaced := make(map[string][]time.Time)
+	for _, e := range events {
+		if !e.recurrenceID.IsZero() {
+			replaced[e.uid] = append(replaced[e.uid], e.recurrenceID)
+		}
+	}
+
+	var expanded []event
+
+	for _, e := range events {
+		if e.rule == "" || !e.recurrenceID.IsZero() {
+			if e.start.Before(to) && e.end.After(from) {
+				expanded = append(expanded, e)
+			}
+			continue
+		}
+
+		r, err := parseRule(e.rule, e.start.Location())
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse rule of event %q", e.summary)
+		}
+
+		var excluded []time.Time
+		excluded = append(excluded, e.exceptions...)
+		excluded = append(excluded, replaced[e.uid]...)
+
+		for _, start := range r.occurrences(e.start, to) {
+			occurrence := e
+			occurrence.start = start
+			occurrence.end = start.Add(e.end.Sub(e.start))
+
+			if containsTime(excluded, start) || !occurrence.end.After(from) {
+				continue
+			}
+
+			expanded = append(expanded, occurrence)
+		}
+	}
+
+	return expanded, nil
+}
+
+// rule represents a recurrence rule. Only daily and weekly rules are supported,
+// which is what on-call rotations are made of.
+type rule struct {
+	freq      string
+	interval  int
+	count     int
+	until     time.Time
+	byDay     []time.Weekday
+	weekStart time.Weekday
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// parseRule parses the value of an RRULE property (e.g. "FREQ=WEEKLY;INTERVAL=2;
+// BYDAY=MO"). Rules with parts that are not supported are rejected rather than
+// expanded incorrectly. UNTIL without a time zone is interpreted in the given
+// location.
+func parseRule(value string, loc *time.Location) (rule, error) {
+	r := rule{
+		interval:  1,
+		weekStart: time.Monday,
+	}
+
+	for _, part := range strings.Split(value, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return rule{}, errors.Errorf("invalid rule part %q", part)
+		}
+
+		key, val := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+
+		var err error
+
+		switch key {
+		case "FREQ":
+			if val != "DAILY" && val != "WEEKLY" {
+				return rule{}, errors.Errorf("frequency %s is not supported", val)
+			}
+			r.freq = val
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(val)
+			if err == nil && r.interval <= 0 {
+				err = errors.Errorf("interval %d is not positive", r.interval)
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(val)
+			if err == nil && r.count <= 0 {
+				err = errors.Errorf("count %d is not positive", r.count)
+			}
+		case "UNTIL":
+			r.until, err = parseDateTime(val, nil, loc)
+			if err == nil && len(val) == len("20060102") {
+				// A date includes occurrences starting at any time of the day.
+				r.until = r.until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			}
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				wd, ok := weekdays[d]
+				if !ok {
+					return rule{}, errors.Errorf("day %q is not supported", d)
+				}
+				r.byDay = append(r.byDay, wd)
+			}
+		case "WKST":
+			wd, ok := weekdays[val]
+			if !ok {
+				return rule{}, errors.Errorf("invalid week start %q", val)
+			}
+			r.weekStart = wd
+		default:
+			return rule{}, errors.Errorf("rule part %s is not supported", key)
+		}
+
+		if err != nil {
+			return rule{}, errors.Wrapf(err, "invalid rule part %s", key)
+		}
+	}
+
+	if r.freq == "" {
+		return rule{}, errors.New("frequency is missing")
+	}
+
+	if r.freq == "DAILY" && len(r.byDay) > 0 {
+		return rule{}, errors.New("days of daily rules are not supported")
+	}
+
+	return r, nil
+}
+
+// occurrences returns start times of occurrences of the rule that start before
+// the given time. The first occurrence starts at the given start time, and
+// later ones keep its time of the day in its location.
+func (r rule) occurrences(start, before time.Time) []time.Time {
+	var (
+		starts []time.Time
+		n      int
+	)
+
+	add := func(t time.Time) bool {
+		if !t.Before(before) || (!r.until.IsZero() && t.After(r.until)) ||
+			(r.count > 0 && n >= r.count) {
+
+			return false
+		}
+		starts = append(starts, t)
+		n++
+		return true
+	}
+
+	if r.freq == "DAILY" || len(r.byDay) == 0 {
+		days := r.interval
+		if r.freq == "WEEKLY" {
+			days *= 7
+		}
+
+		for i := 0; ; i++ {
+			if !add(start.AddDate(0, 0, i*days)) {
+				return starts
+			}
+		}
+	}
+
+	// The first occurrence counts even if it does not fall on any of the days.
+	if !add(start) {
+		return starts
+	}
+
+	// Weekly rules with days repeat the days in every interval-th week counted
+	// from the week of the start.
+	offset := (int(start.Weekday()) - int(r.weekStart) + 7) % 7
+	week := start.AddDate(0, 0, -offset)
+
+	for ; ; week = week.AddDate(0, 0, 7*r.interval) {
+		for _, wd := range r.daysOfWeek() {
+			t := week.AddDate(0, 0, (int(wd)-int(r.weekStart)+7)%7)
+			if !t.After(start) {
+				continue
+			}
+			if !add(t) {
+				return starts
+			}
+		}
+	}
+}
+
+// daysOfWeek returns the days of the rule sorted from the start of the week.
+func (r rule) daysOfWeek() []time.Weekday {
+	days := make([]time.Weekday, 0, len(r.byDay))
+
+	for i := 0; i < 7; i++ {
+		wd := time.Weekday((int(r.weekStart) + i) % 7)
+		for _, d := range r.byDay {
+			if d == wd {
+				days = append(days, wd)
+				break
+			}
+		}
+	}
+
+	return days
+}
+
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, tt := range times {
+		if tt.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// unfoldLines reads content lines joining the ones folded across several lines.
+func unfoldLines(r io.Reader) ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) &&
+			len(lines) > 0 {
+
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// parseContentLine splits a content line (e.g. "DTSTART;TZID=Asia/Singapore:
+// 20210215T090000") into its name, parameters and value.
+func parseContentLine(line string) (string, map[string]string, string) {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return line, nil, ""
+	}
+
+	parts := strings.Split(line[:i], ";")
+
+	params := make(map[string]string)
+	for _, p := range parts[1:] {
+		if kv := strings.SplitN(p, "=", 2); len(kv) == 2 {
+			params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+
+	return strings.ToUpper(parts[0]), params, line[i+1:]
+}
+
+func parseDateTime(value string, params map[string]string, loc *time.Location,
+) (time.Time, error) {
+
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		return time.ParseInLocation("20060102", value, loc)
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+
+	if tzid, ok := params["TZID"]; ok {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+
+	return time.ParseInLocation("20060102T150405", value, loc)
+}
+
+func unescapeText(s string) string {
+	return strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`).
+		Replace(s)
+}
@@ -0,0 +1,222 @@
+package ics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var singapore = time.FixedZone("SGT", 8*60*60)
+
+func calendar(events ...string) string {
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0"}
+	for _, e := range events {
+		lines = append(lines, "BEGIN:VEVENT", e, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+	return strings.Join(lines, "\r\n")
+}
+
+func TestExpandEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		calendar string
+		day      time.Time
+		expected []string
+	}{
+		{
+			name: "single event",
+			calendar: calendar(
+				"SUMMARY:Alice\r\nDTSTART:20210215T010000Z\r\nDTEND:20210216T010000Z",
+			),
+			day:      time.Date(2021, 2, 16, 0, 0, 0, 0, singapore),
+			expected: []string{"Alice 2021-02-15T09:00 2021-02-16T09:00"},
+		},
+		{
+			name: "daily rule with count within the count",
+			calendar: calendar(
+				"SUMMARY:Alice\r\nDTSTART:20210201T090000\r\nDTEND:20210201T180000\r\n" +
+					"RRULE:FREQ=DAILY;COUNT=3",
+			),
+			day:      time.Date(2021, 2, 3, 0, 0, 0, 0, singapore),
+			expected: []string{"Alice 2021-02-03T09:00 2021-02-03T18:00"},
+		},
+		{
+			name: "daily rule with count after the count",
+			calendar: calendar(
+				"SUMMARY:Alice\r\nDTSTART:20210201T090000\r\nDTEND:20210201T180000\r\n" +
+					"RRULE:FREQ=DAILY;COUNT=3",
+			),
+			day: time.Date(2021, 2, 4, 0, 0, 0, 0, singapore),
+		},
+		{
+			name: "daily rule with interval",
+			calendar: calendar(
+				"SUMMARY:Alice\r\nDTSTART:20210201T090000\r\nDTEND:20210201T180000\r\n" +
+					"RRULE:FREQ=DAILY;INTERVAL=2",
+			),
+			day:      time.Date(2021, 2, 5, 0, 0, 0, 0, singapore),
+			expected: []string{"Alice 2021-02-05T09:00 2021-02-05T18:00"},
+		},
+		{
+			name: "daily rule with interval on a skipped day",
+			calendar: calendar(
+				"SUMMARY:Alice\r\nDTSTART:20210201T090000\r\nDTEND:20210201T180000\r\n" +
+					"RRULE:FREQ=DAILY;INTERVAL=2",
+			),
+			day: time.Date(2021, 2, 6, 0, 0, 0, 0, singapore),
+		},
+		{
+			name: "daily rule until a date",
+			calendar: calendar(
+				"SUMMARY:Alice\r\nDTSTART:20210201T090000\r\nDTEND:20210201T180000\r\n" +
+					"RRULE:FREQ=DAILY;UNTIL=20210203",
+			),
+			day:      time.Date(2021, 2, 3, 0, 0, 0, 0, singapore),
+			expected: []string{"Alice 2021-02-03T09:00 2021-02-03T18:00"},
+		},
+		{
+			name: "daily rule until a time",
+			calendar: calendar(
+				"SUMMARY:Alice\r\nDTSTART:20210201T090000\r\nDTEND:20210201T180000\r\n" +
+					"RRULE:FREQ=DAILY;UNTIL=20210202T120000Z",
+			),
+			day: time.Date(2021, 2, 3, 0, 0, 0, 0, singapore),
+		},
+		{
+			name: "weekly rotation overlapping the day",
+			calendar: calendar(
+				"SUMMARY:Alice\r\nDTSTART;TZID=Asia/Singapore:20210201T090000\r\n"+
+					"DTEND;TZID=Asia/Singapore:20210208T090000\r\n"+
+					"RRULE:FREQ=WEEKLY;INTERVAL=2",
+				"SUMMARY:Bob\r\nDTSTART;TZID=Asia/Singapore:20210208T090000\r\n"+
+					"DTEND;TZID=Asia/Singapore:20210215T090000\r\n"+
+					"RRULE:FREQ=WEEKLY;INTERVAL=2",
+			),
+			day: time.Date(2021, 2, 15, 0, 0, 0, 0, singapore),
+			expected: []string{
+				"Alice 2021-02-15T09:00 2021-02-22T09:00",
+				"Bob 2021-02-08T09:00 2021-02-15T09:00",
+			},
+		},
+		{
+			name: "weekly rule with days",
+			calendar: calendar(
+				"SUMMARY:Alice\r\nDTSTART:20210201T090000\r\nDTEND:20210201T180000\r\n" +
+					"RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=4",
+			),
+			day:      time.Date(2021, 2, 8, 0, 0, 0, 0, singapore),
+			expected: []string{"Alice 2021-02-08T09:00 2021-02-08T18:00"},
+		},
+		{
+			name: "weekly rule with days on another day",
+			calendar: calendar(
+				"SUMMARY:Alice\r\nDTSTART:20210201T090000\r\nDTEND:20210201T180000\r\n" +
+					"RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR",
+			),
+			day: time.Date(2021, 2, 9, 0, 0, 0, 0, singapore),
+		},
+		{
+			name: "excluded occurrence",
+			calendar: calendar(
+				"SUMMARY:Alice\r\nDTSTART:20210201T090000\r\nDTEND:20210201T180000\r\n" +
+					"RRULE:FREQ=DAILY\r\nEXDATE:20210203T090000,20210204T090000",
+			),
+			day: time.Date(2021, 2, 4, 0, 0, 0, 0, singapore),
+		},
+		{
+			name: "replaced occurrence",
+			calendar: calendar(
+				"UID:rotation\r\nSUMMARY:Alice\r\nDTSTART:20210201T090000\r\n"+
+					"DTEND:20210201T180000\r\nRRULE:FREQ=DAILY",
+				"UID:rotation\r\nRECURRENCE-ID:20210203T090000\r\nSUMMARY:Bob\r\n"+
+					"DTSTART:20210203T100000\r\nDTEND:20210203T190000",
+			),
+			day:      time.Date(2021, 2, 3, 0, 0, 0, 0, singapore),
+			expected: []string{"Bob 2021-02-03T10:00 2021-02-03T19:00"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			events, err := parseEvents(strings.NewReader(test.calendar), singapore)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			events, err = expandEvents(events, test.day, test.day.AddDate(0, 0, 1))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var actual []string
+			for _, e := range events {
+				actual = append(actual, e.summary+" "+
+					e.start.In(singapore).Format("2006-01-02T15:04")+" "+
+					e.end.In(singapore).Format("2006-01-02T15:04"),
+				)
+			}
+
+			if strings.Join(actual, "\n") != strings.Join(test.expected, "\n") {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestExpandEvents_UnsupportedRule(t *testing.T) {
+	for _, rule := range []string{
+		"FREQ=MONTHLY",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ=DAILY;INTERVAL=0",
+		"INTERVAL=2",
+	} {
+		events, err := parseEvents(strings.NewReader(calendar(
+			"SUMMARY:Alice\r\nDTSTART:20210201T090000\r\nRRULE:"+rule,
+		)), singapore)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		day := time.Date(2021, 2, 1, 0, 0, 0, 0, singapore)
+
+		if _, err := expandEvents(events, day, day.AddDate(0, 0, 1)); err == nil {
+			t.Errorf("expected an error for rule %s", rule)
+		}
+	}
+}
+
+func TestSchedule_GetOnCallShiftsByDay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/calendar")
+		_, _ = w.Write([]byte(calendar(
+			"SUMMARY:Alice\r\nDTSTART:20210201T090000\r\nDTEND:20210208T090000\r\n" +
+				"RRULE:FREQ=WEEKLY",
+		)))
+	}))
+	defer srv.Close()
+
+	s := NewSchedule(srv.Client(), srv.URL, "Platform", 1)
+
+	shifts, err := s.GetOnCallShiftsByDay(time.Date(2021, 2, 17, 12, 0, 0, 0, singapore))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(shifts) != 1 {
+		t.Fatalf("expected a single shift, got %+v", shifts)
+	}
+
+	s0 := shifts[0]
+	if s0.PersonName != "Alice" || s0.EscalationPolicy != "Platform" || s0.Level != 1 {
+		t.Errorf("unexpected shift %+v", s0)
+	}
+	if !s0.StartsAt.Equal(time.Date(2021, 2, 15, 9, 0, 0, 0, singapore)) ||
+		!s0.EndsAt.Equal(time.Date(2021, 2, 22, 9, 0, 0, 0, singapore)) {
+
+		t.Errorf("unexpected shift times %v - %v", s0.StartsAt, s0.EndsAt)
+	}
+}
@@ -0,0 +1,136 @@
+package jenkins
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/httpjson"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+// Jenkins provides communication with Jenkins remote access API.
+type Jenkins struct {
+	client   *http.Client
+	baseURL  string
+	username string
+	apiToken string
+	jobs     []string
+}
+
+// Config holds Jenkins' configuration.
+type Config struct {
+	BaseURL  string
+	Username string
+	APIToken string
+
+	// Jobs are full names of jobs building default branches. Jobs nested in
+	// folders or multibranch pipelines are separated with slashes
+	// (e.g. mobile/backend/main).
+	Jobs []string
+}
+
+// New initializes a new Jenkins.
+func New(c *http.Client, conf Config) Jenkins {
+	return Jenkins{
+		client:   c,
+		baseURL:  strings.TrimSuffix(conf.BaseURL, "/"),
+		username: conf.Username,
+		apiToken: conf.APIToken,
+		jobs:     conf.Jobs,
+	}
+}
+
+type jobResponse struct {
+	FullName string `json:"fullName"`
+	Builds   []struct {
+		Number   int     `json:"number"`
+		URL      string  `json:"url"`
+		Building bool    `json:"building"`
+		Result   *string `json:"result"`
+
+		// Timestamp and Duration are in milliseconds.
+		Timestamp int64 `json:"timestamp"`
+		Duration  int64 `json:"duration"`
+	} `json:"builds"`
+}
+
+// GetDefaultBranchBuilds implements newspaper.BuildSource interface and fetches
+// the most recent builds of the jobs. Jenkins does not know which branch a job
+// builds, so builds are reported without one.
+func (j Jenkins) GetDefaultBranchBuilds(limit int) ([]newspaper.Build, error) {
+	var builds []newspaper.Build
+
+	for _, name := range j.jobs {
+		var job jobResponse
+		if err := j.get(toJobPath(name), fmt.Sprintf(
+			"fullName,builds[number,url,building,result,timestamp,duration]{0,%d}", limit,
+		), &job); err != nil {
+			return nil, errors.Wrapf(err, "could not fetch job %s", name)
+		}
+
+		for _, jb := range job.Builds {
+			b := newspaper.Build{
+				Repository: job.FullName,
+				ID:         "#" + strconv.Itoa(jb.Number),
+				URL:        jb.URL,
+				StartedAt:  time.Unix(0, jb.Timestamp*int64(time.Millisecond)),
+				Status:     toBuildStatus(jb.Building, jb.Result),
+			}
+
+			if !jb.Building {
+				b.FinishedAt = b.StartedAt.Add(time.Duration(jb.Duration) * time.Millisecond)
+			}
+
+			builds = append(builds, b)
+		}
+	}
+
+	return builds, nil
+}
+
+// toJobPath turns the given full name of a job into the path of its page
+// (e.g. mobile/backend turns into /job/mobile/job/backend).
+func toJobPath(name string) string {
+	var path string
+	for _, part := range strings.Split(name, "/") {
+		path += "/job/" + url.PathEscape(part)
+	}
+	return path
+}
+
+func toBuildStatus(building bool, result *string) newspaper.BuildStatus {
+	if building || result == nil {
+		return newspaper.BuildStatusRunning
+	}
+
+	switch *result {
+	case "SUCCESS":
+		return newspaper.BuildStatusPassed
+	case "FAILURE", "UNSTABLE":
+		return newspaper.BuildStatusFailed
+	default:
+		return newspaper.BuildStatusCanceled
+	}
+}
+
+// get sends a GET request to the JSON API of the given page limiting the response
+// to the given tree of fields and decodes it into v.
+func (j Jenkins) get(path, tree string, v interface{}) error {
+	req, err := http.NewRequest(
+		http.MethodGet, j.baseURL+path+"/api/json?tree="+url.QueryEscape(tree), nil,
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not prepare request")
+	}
+
+	if j.username != "" {
+		req.SetBasicAuth(j.username, j.apiToken)
+	}
+
+	return httpjson.Do(j.client, req, v)
+}
@@ -0,0 +1,153 @@
+package jira
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+// defaultStoryPointsField is the field holding story point estimates in Jira
+// Cloud.
+const defaultStoryPointsField = "customfield_10016"
+
+// sprint represents a sprint returned by Jira Agile API. It differs from
+// jira.Sprint by having the sprint's goal.
+type sprint struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Goal      string    `json:"goal"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+}
+
+type sprintsResponse struct {
+	Values []sprint `json:"values"`
+}
+
+// GetActiveSprint implements newspaper.SprintBoard interface and fetches the
+// active sprint of the board along with its tickets. Tickets are completed when
+// they get resolved and are added mid-sprint when they join the sprint after it
+// has started.
+func (j Jira) GetActiveSprint() (newspaper.Sprint, bool, error) {
+	req, err := j.client.NewRequest(http.MethodGet, fmt.Sprintf(
+		"rest/agile/1.0/board/%d/sprint?state=active", j.boardID,
+	), nil)
+	if err != nil {
+		return newspaper.Sprint{}, false, errors.Wrap(err, "could not prepare request")
+	}
+
+	var resp sprintsResponse
+	if _, err := j.client.Do(req, &resp); err != nil {
+		return newspaper.Sprint{}, false, errors.Wrap(err, "could not fetch sprints")
+	}
+
+	if len(resp.Values) == 0 {
+		return newspaper.Sprint{}, false, nil
+	}
+
+	// Boards with parallel sprints may have several active ones, in which case
+	// the earliest one is reported.
+	active := resp.Values[0]
+	for _, s := range resp.Values[1:] {
+		if s.StartDate.Before(active.StartDate) {
+			active = s
+		}
+	}
+
+	s := newspaper.Sprint{
+		Name:     active.Name,
+		Goal:     active.Goal,
+		StartsAt: active.StartDate,
+		EndsAt:   active.EndDate,
+	}
+
+	if err := j.client.Issue.SearchPages(
+		fmt.Sprintf("sprint = %d", active.ID),
+		&jira.SearchOptions{
+			StartAt:    0,
+			MaxResults: 50,
+			Expand:     "changelog",
+			Fields:     []string{"created", "resolutiondate", j.storyPointsField},
+		},
+		func(i jira.Issue) error {
+			t := newspaper.SprintTicket{
+				ID:          i.Key,
+				CompletedAt: time.Time(i.Fields.Resolutiondate),
+			}
+
+			t.StoryPoints, _ = i.Fields.Unknowns.Float(j.storyPointsField)
+
+			addedAt, ok := getTimeAddedToSprint(i, active.ID)
+			if !ok {
+				// Tickets created right in the sprint have no changelog of joining it.
+				addedAt = time.Time(i.Fields.Created)
+			}
+
+			if addedAt.After(active.StartDate) {
+				t.AddedAt = addedAt
+			}
+
+			s.Tickets = append(s.Tickets, t)
+			return nil
+		},
+	); err != nil {
+		return newspaper.Sprint{}, false, err
+	}
+
+	return s, true, nil
+}
+
+// getTimeAddedToSprint returns the time the ticket most recently joined the
+// sprint of the given ID according to the ticket's changelog.
+func getTimeAddedToSprint(i jira.Issue, sprintID int) (time.Time, bool) {
+	id := strconv.Itoa(sprintID)
+
+	var (
+		addedAt time.Time
+		found   bool
+	)
+
+	for _, history := range i.Changelog.Histories {
+		for _, item := range history.Items {
+			if item.Field != "Sprint" {
+				continue
+			}
+
+			from, _ := item.From.(string)
+			to, _ := item.To.(string)
+
+			if !containsID(to, id) || containsID(from, id) {
+				continue
+			}
+
+			t, err := history.CreatedTime()
+			if err != nil {
+				continue
+			}
+
+			if !found || t.After(addedAt) {
+				addedAt = t
+				found = true
+			}
+		}
+	}
+
+	return addedAt, found
+}
+
+// containsID reports whether the given comma-separated list of IDs contains the
+// given ID.
+func containsID(ids, id string) bool {
+	for _, v := range strings.Split(ids, ",") {
+		if strings.TrimSpace(v) == id {
+			return true
+		}
+	}
+	return false
+}
@@ -1,14 +1,27 @@
 package jira
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
 	"github.com/ztimes2/dailybugle/internal/newspaper"
 )
 
 // Jira provides communication with Jira API.
 type Jira struct {
-	client  *jira.Client
-	baseURL string
+	client           *jira.Client
+	baseURL          string
+	incidentsJQL     string
+	bugsJQL          string
+	escapedBugsJQL   string
+	reopenedStatus   string
+	boardID          int
+	storyPointsField string
 }
 
 // Config holds Jira's configuration.
@@ -16,10 +29,60 @@ type Config struct {
 	BaseURL  string
 	Username string
 	APIToken string
+
+	// IncidentsJQL is a JQL query matching tickets that represent incidents
+	// (e.g. project = INC).
+	IncidentsJQL string
+
+	// BugsJQL is a JQL query matching bugs. Defaults to bugs of the team's
+	// project.
+	BugsJQL string
+
+	// EscapedBugsJQL is a JQL query matching bugs that escaped to production.
+	// Defaults to bugs labelled with "production".
+	EscapedBugsJQL string
+
+	// ReopenedStatus is the status bugs transition to when they get reopened.
+	// Defaults to "Reopened".
+	ReopenedStatus string
+
+	// BoardID is the ID of the agile board whose sprints are tracked.
+	BoardID int
+
+	// StoryPointsField is the ID of the custom field holding story point
+	// estimates. Defaults to the field used by Jira Cloud.
+	StoryPointsField string
 }
 
+// orderByRegexp matches ORDER BY clauses, which cannot be part of configured JQL
+// queries since they are combined with further conditions.
+var orderByRegexp = regexp.MustCompile(`(?i)\border\s+by\b`)
+
 // New initializes a new Jira.
 func New(conf Config) (Jira, error) {
+	for _, q := range []struct{ name, jql string }{
+		{"incidents", conf.IncidentsJQL},
+		{"bugs", conf.BugsJQL},
+		{"escaped bugs", conf.EscapedBugsJQL},
+	} {
+		if orderByRegexp.MatchString(q.jql) {
+			return Jira{}, errors.Errorf("%s JQL must not contain ORDER BY", q.name)
+		}
+	}
+
+	if conf.BugsJQL == "" {
+		conf.BugsJQL = projectJQL + ` AND issuetype = Bug`
+	}
+	if conf.EscapedBugsJQL == "" {
+		conf.EscapedBugsJQL = `(` + conf.BugsJQL + `) AND labels = production`
+	}
+	if conf.ReopenedStatus == "" {
+		conf.ReopenedStatus = "Reopened"
+	}
+	if conf.StoryPointsField == "" {
+		conf.StoryPointsField = defaultStoryPointsField
+	}
+
 	t := jira.BasicAuthTransport{
 		Username: conf.Username,
 		Password: conf.APIToken,
@@ -31,36 +94,278 @@ func New(conf Config) (Jira, error) {
 	}
 
 	return Jira{
-		client:  client,
-		baseURL: conf.BaseURL,
+		client:           client,
+		baseURL:          conf.BaseURL,
+		incidentsJQL:     conf.IncidentsJQL,
+		bugsJQL:          conf.BugsJQL,
+		escapedBugsJQL:   conf.EscapedBugsJQL,
+		reopenedStatus:   conf.ReopenedStatus,
+		boardID:          conf.BoardID,
+		storyPointsField: conf.StoryPointsField,
 	}, nil
 }
 
+// projectJQL is a JQL query matching tickets of the team's project.
+const projectJQL = `project = "Mobile Backend"`
+
 // GetTicketsAwaitingReview implements newspaper.Ticketer interface and fetches
 // Jira tickets that are waiting for code review.
 func (j Jira) GetTicketsAwaitingReview() ([]newspaper.Ticket, error) {
+	return j.searchTickets(projectJQL + ` AND status = "Awaiting Review"`)
+}
+
+// GetTicketsInProgress implements newspaper.StuckTicketer interface and fetches
+// Jira tickets that are in progress.
+func (j Jira) GetTicketsInProgress() ([]newspaper.Ticket, error) {
+	return j.searchTickets(projectJQL + ` AND status = "In Progress"`)
+}
+
+// GetFlaggedTickets implements newspaper.StuckTicketer interface and fetches
+// unresolved Jira tickets that are flagged as impediments.
+func (j Jira) GetFlaggedTickets() ([]newspaper.Ticket, error) {
+	return j.searchTickets(projectJQL + ` AND Flagged is not EMPTY AND resolution = EMPTY`)
+}
+
+// GetBlockedTickets implements newspaper.StuckTicketer interface and fetches
+// unresolved Jira tickets that are blocked by other unresolved tickets.
+func (j Jira) GetBlockedTickets() ([]newspaper.BlockedTicket, error) {
+	var tickets []newspaper.BlockedTicket
+
+	if err := j.client.Issue.SearchPages(
+		projectJQL+` AND issueLinkType = "is blocked by" AND resolution = EMPTY`,
+		&jira.SearchOptions{
+			StartAt:    0,
+			MaxResults: 50,
+			Expand:     "changelog",
+			Fields:     []string{"summary", "status", "issuelinks"},
+		},
+		func(i jira.Issue) error {
+			t := newspaper.BlockedTicket{
+				Ticket: j.toTicket(i),
+			}
+
+			for _, l := range i.Fields.IssueLinks {
+				b := l.InwardIssue
+				if b == nil || !strings.EqualFold(l.Type.Inward, "is blocked by") {
+					continue
+				}
+
+				if b.Fields != nil && b.Fields.Status != nil &&
+					b.Fields.Status.StatusCategory.Key == jira.StatusCategoryComplete {
+					continue
+				}
+
+				t.BlockedBy = append(t.BlockedBy, newspaper.Ticket{
+					ID:  b.Key,
+					URL: j.toURL(*b),
+				})
+			}
+
+			if len(t.BlockedBy) > 0 {
+				tickets = append(tickets, t)
+			}
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	return tickets, nil
+}
+
+// searchTickets fetches Jira tickets matching the given JQL query along with the
+// time of their transition to the current status.
+func (j Jira) searchTickets(jql string) ([]newspaper.Ticket, error) {
 	var tickets []newspaper.Ticket
 
 	if err := j.client.Issue.SearchPages(
-		`project = "Mobile Backend" AND status = "Awaiting Review"`,
+		jql,
+		&jira.SearchOptions{
+			StartAt:    0,
+			MaxResults: 50,
+			Expand:     "changelog",
+			Fields:     []string{"summary", "status", "created"},
+		},
+		func(i jira.Issue) error {
+			tickets = append(tickets, j.toTicket(i))
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	return tickets, nil
+}
+
+func (j Jira) toTicket(i jira.Issue) newspaper.Ticket {
+	t := newspaper.Ticket{
+		ID:            i.Key,
+		URL:           j.toURL(i),
+		Summary:       i.Fields.Summary,
+		CurrentStatus: i.Fields.Status.Name,
+	}
+
+	// Tickets that never transitioned have been in their status since they were
+	// created.
+	t.CurrentStatusSince = time.Time(i.Fields.Created)
+	if h, ok := getTransitionToCurrentStatus(i); ok {
+		if at, err := h.CreatedTime(); err == nil {
+			t.CurrentStatusSince = at
+		}
+	}
+
+	return t
+}
+
+// toJQLTime formats the given time as a JQL value relative to the current time
+// (e.g. -1440m). Absolute values are interpreted by Jira in the time zone of the
+// API user, which does not necessarily match the newspaper's one.
+func toJQLTime(t time.Time) string {
+	minutes := int64((newspaper.TimeNowFunc().Sub(t) + time.Minute - 1) / time.Minute)
+	return fmt.Sprintf("%+dm", -minutes)
+}
+
+// GetIncidentsSince implements newspaper.IncidentSource interface and fetches
+// incident tickets that were created or resolved since the given time as well as
+// the ones that are still unresolved. Severity is taken from the ticket's priority.
+func (j Jira) GetIncidentsSince(t time.Time) ([]newspaper.Incident, error) {
+	since := toJQLTime(t)
+
+	var incidents []newspaper.Incident
+
+	if err := j.client.Issue.SearchPages(
+		fmt.Sprintf(
+			`(%s) AND (resolution = EMPTY OR resolutiondate >= "%s" OR created >= "%s")`,
+			j.incidentsJQL, since, since,
+		),
+		&jira.SearchOptions{
+			StartAt:    0,
+			MaxResults: 50,
+			Fields:     []string{"summary", "priority", "created", "resolutiondate"},
+		},
+		func(i jira.Issue) error {
+			incident := newspaper.Incident{
+				ID:         i.Key,
+				URL:        j.toURL(i),
+				Title:      i.Fields.Summary,
+				OpenedAt:   time.Time(i.Fields.Created),
+				ResolvedAt: time.Time(i.Fields.Resolutiondate),
+			}
+
+			if i.Fields.Priority != nil {
+				incident.Severity = newspaper.ParseSeverity(i.Fields.Priority.Name)
+			}
+
+			incidents = append(incidents, incident)
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	return incidents, nil
+}
+
+// GetBugsCreatedSince implements newspaper.BugTracker interface and fetches bugs
+// created since the given time.
+func (j Jira) GetBugsCreatedSince(t time.Time) ([]newspaper.Bug, error) {
+	return j.searchBugs(fmt.Sprintf(`(%s) AND created >= "%s" ORDER BY created DESC`,
+		j.bugsJQL, toJQLTime(t),
+	))
+}
+
+// GetBugsReopenedSince implements newspaper.BugTracker interface and fetches bugs
+// reopened since the given time.
+func (j Jira) GetBugsReopenedSince(t time.Time) ([]newspaper.Bug, error) {
+	return j.searchBugs(fmt.Sprintf(`(%s) AND status CHANGED TO "%s" AFTER "%s"`,
+		j.bugsJQL, j.reopenedStatus, toJQLTime(t),
+	))
+}
+
+// GetEscapedBugsCreatedSince implements newspaper.BugTracker interface and fetches
+// bugs that escaped to production created since the given time.
+func (j Jira) GetEscapedBugsCreatedSince(t time.Time) ([]newspaper.Bug, error) {
+	return j.searchBugs(fmt.Sprintf(`(%s) AND created >= "%s"`,
+		j.escapedBugsJQL, toJQLTime(t),
+	))
+}
+
+func (j Jira) searchBugs(jql string) ([]newspaper.Bug, error) {
+	var bugs []newspaper.Bug
+
+	if err := j.client.Issue.SearchPages(
+		jql,
+		&jira.SearchOptions{
+			StartAt:    0,
+			MaxResults: 50,
+			Fields:     []string{"summary", "priority", "components", "created"},
+		},
+		func(i jira.Issue) error {
+			b := newspaper.Bug{
+				ID:        i.Key,
+				URL:       j.toURL(i),
+				Summary:   i.Fields.Summary,
+				CreatedAt: time.Time(i.Fields.Created),
+			}
+
+			if i.Fields.Priority != nil {
+				b.Priority = i.Fields.Priority.Name
+			}
+
+			for _, c := range i.Fields.Components {
+				b.Components = append(b.Components, c.Name)
+			}
+
+			bugs = append(bugs, b)
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	return bugs, nil
+}
+
+// GetTicketsCompletedSince implements newspaper.TicketHistorian interface and
+// fetches tickets resolved since the given time along with the time they spent in
+// each status according to their changelog. Work on a ticket is considered started
+// when it first moves to a status of the "In Progress" category.
+func (j Jira) GetTicketsCompletedSince(t time.Time) ([]newspaper.CompletedTicket, error) {
+	statuses, _, err := j.client.Status.GetAllStatuses()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch statuses")
+	}
+
+	inProgress := make(map[string]bool)
+	for _, s := range statuses {
+		if s.StatusCategory.Key == jira.StatusCategoryInProgress {
+			inProgress[s.ID] = true
+		}
+	}
+
+	var tickets []newspaper.CompletedTicket
+
+	if err := j.client.Issue.SearchPages(
+		fmt.Sprintf(`%s AND resolved >= "%s"`, projectJQL, toJQLTime(t)),
 		&jira.SearchOptions{
 			StartAt:    0,
 			MaxResults: 50,
 			Expand:     "changelog",
-			Fields:     []string{"summary", "status"},
+			Fields:     []string{"status", "created", "resolutiondate"},
 		},
 		func(i jira.Issue) error {
-			t := newspaper.Ticket{
-				ID:            i.Key,
-				URL:           j.toURL(i),
-				Summary:       i.Fields.Summary,
-				CurrentStatus: i.Fields.Status.Name,
+			ticket := newspaper.CompletedTicket{
+				ID:          i.Key,
+				URL:         j.toURL(i),
+				CreatedAt:   time.Time(i.Fields.Created),
+				CompletedAt: time.Time(i.Fields.Resolutiondate),
 			}
 
-			h, _ := getTransitionToCurrentStatus(i)
-			t.CurrentStatusSince, _ = h.CreatedTime()
+			ticket.StartedAt, ticket.TimeInStatus = getStatusHistory(
+				i, ticket.CompletedAt, inProgress,
+			)
 
-			tickets = append(tickets, t)
+			tickets = append(tickets, ticket)
 			return nil
 		},
 	); err != nil {
@@ -70,16 +375,95 @@ func (j Jira) GetTicketsAwaitingReview() ([]newspaper.Ticket, error) {
 	return tickets, nil
 }
 
+// statusTransition represents a change of a ticket's status.
+type statusTransition struct {
+	from string
+	to   string
+	toID string
+	at   time.Time
+}
+
+// getStatusHistory returns the time the ticket first moved to one of the given
+// in-progress statuses and the time the ticket spent in each status until the
+// given time.
+func getStatusHistory(i jira.Issue, until time.Time, inProgress map[string]bool,
+) (time.Time, map[string]time.Duration) {
+
+	var transitions []statusTransition
+
+	for _, history := range i.Changelog.Histories {
+		at, err := history.CreatedTime()
+		if err != nil {
+			continue
+		}
+
+		for _, item := range history.Items {
+			if item.Field == "status" {
+				toID, _ := item.To.(string)
+				transitions = append(transitions, statusTransition{
+					from: item.FromString,
+					to:   item.ToString,
+					toID: toID,
+					at:   at,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(transitions, func(a, b int) bool {
+		return transitions[a].at.Before(transitions[b].at)
+	})
+
+	var startedAt time.Time
+	timeInStatus := make(map[string]time.Duration)
+
+	since := time.Time(i.Fields.Created)
+
+	for _, tr := range transitions {
+		if tr.at.After(until) {
+			break
+		}
+		if startedAt.IsZero() && inProgress[tr.toID] {
+			startedAt = tr.at
+		}
+		timeInStatus[tr.from] += tr.at.Sub(since)
+		since = tr.at
+	}
+
+	return startedAt, timeInStatus
+}
+
+// getTransitionToCurrentStatus returns the most recent transition of the ticket
+// into its current status.
 func getTransitionToCurrentStatus(i jira.Issue) (jira.ChangelogHistory, bool) {
+	var (
+		latest   jira.ChangelogHistory
+		latestAt time.Time
+		found    bool
+	)
+
+	if i.Changelog == nil {
+		return latest, false
+	}
+
 	for _, history := range i.Changelog.Histories {
 		for _, item := range history.Items {
-			if item.Field == "status" && item.ToString == i.Fields.Status.Name {
-				return history, true
+			if item.Field != "status" || item.ToString != i.Fields.Status.Name {
+				continue
+			}
+
+			at, err := history.CreatedTime()
+			if err != nil {
+				continue
+			}
+
+			if !found || at.After(latestAt) {
+				latest, latestAt, found = history, at, true
 			}
 		}
 	}
 
-	return jira.ChangelogHistory{}, false
+	return latest, found
 }
 
 func (j Jira) toURL(i jira.Issue) string {
@@ -0,0 +1,140 @@
+package junit
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+// timestampLayout is the layout of testsuite's timestamp attribute, which comes
+// without a timezone.
+const timestampLayout = "2006-01-02T15:04:05"
+
+// testSuite represents both testsuites and testsuite elements since reports may
+// have either of them as the root element.
+type testSuite struct {
+	Name      string      `xml:"name,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Suites    []testSuite `xml:"testsuite"`
+	Cases     []testCase  `xml:"testcase"`
+}
+
+type testCase struct {
+	ClassName string     `xml:"classname,attr"`
+	Name      string     `xml:"name,attr"`
+	Failures  []struct{} `xml:"failure"`
+	Errors    []struct{} `xml:"error"`
+	Skipped   *struct{}  `xml:"skipped"`
+}
+
+// Parse reads a JUnit XML report of tests that ran on the given commit. Suites
+// without a timestamp are considered to have run at the given time. Skipped tests
+// are left out.
+func Parse(r io.Reader, commit string, ranAt time.Time) ([]newspaper.TestResult, error) {
+	var root testSuite
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, errors.Wrap(err, "could not decode report")
+	}
+
+	return toTestResults(root, commit, ranAt), nil
+}
+
+func toTestResults(s testSuite, commit string, ranAt time.Time) []newspaper.TestResult {
+	if t, err := time.ParseInLocation(timestampLayout, s.Timestamp, time.UTC); err == nil {
+		ranAt = t
+	}
+
+	var results []newspaper.TestResult
+
+	for _, c := range s.Cases {
+		if c.Skipped != nil {
+			continue
+		}
+
+		suite := c.ClassName
+		if suite == "" {
+			suite = s.Name
+		}
+
+		results = append(results, newspaper.TestResult{
+			Suite:  suite,
+			Name:   c.Name,
+			Commit: commit,
+			Passed: len(c.Failures) == 0 && len(c.Errors) == 0,
+			RanAt:  ranAt,
+		})
+	}
+
+	for _, nested := range s.Suites {
+		results = append(results, toTestResults(nested, commit, ranAt)...)
+	}
+
+	return results
+}
+
+// Directory provides functionality for reading JUnit XML reports from a local
+// directory. Reports are expected to be grouped by the commit they ran on
+// (e.g. <dir>/<commit>/report.xml), and files of any depth below a commit's
+// directory are read.
+type Directory struct {
+	dir string
+}
+
+// NewDirectory initializes a new Directory.
+func NewDirectory(dir string) Directory {
+	return Directory{
+		dir: dir,
+	}
+}
+
+// GetTestResultsSince implements newspaper.TestResultSource interface and reads
+// results of reports that were modified since the given time.
+func (d Directory) GetTestResultsSince(t time.Time) ([]newspaper.TestResult, error) {
+	var results []newspaper.TestResult
+
+	if err := filepath.Walk(d.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !strings.EqualFold(filepath.Ext(path), ".xml") ||
+			info.ModTime().Before(t) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(d.dir, path)
+		if err != nil {
+			return err
+		}
+
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) < 2 {
+			// Reports outside of commits' directories cannot be attributed.
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		r, err := Parse(f, parts[0], info.ModTime())
+		if err != nil {
+			return errors.Wrapf(err, "could not parse %s", rel)
+		}
+
+		results = append(results, r...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,317 @@
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/atomicfile"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	lockRetryInterval = 100 * time.Millisecond
+	lockTimeout       = 10 * time.Second
+
+	// staleLockAge is the age after which a lock file is considered to be left
+	// behind by a process that was killed while holding it. The lock is only
+	// held while the ledger's file is read and written, so a healthy process
+	// never holds it anywhere near that long.
+	staleLockAge = time.Minute
+
+	// pendingRecordTTL is the time after which a publication that has been
+	// claimed but never published is considered to be abandoned by a process
+	// that crashed in the middle of publishing, so that it can be claimed again.
+	pendingRecordTTL = 30 * time.Minute
+)
+
+// File implements newspaper.Ledger interface and keeps records of published
+// issues in a local JSON file. Concurrent access from several processes is
+// guarded by a lock file placed next to the ledger's file.
+type File struct {
+	path string
+}
+
+// NewFile initializes a new File.
+func NewFile(path string) File {
+	return File{
+		path: path,
+	}
+}
+
+type record struct {
+	Edition           string    `json:"edition"`
+	Destination       string    `json:"destination,omitempty"`
+	Date              string    `json:"date"`
+	PublishedAt       time.Time `json:"published_at,omitempty"`
+	ChannelID         string    `json:"channel_id,omitempty"`
+	MessageTimestamps []string  `json:"message_timestamps,omitempty"`
+
+	// ReservedAt is the time the publication was claimed at. Claims recorded
+	// without it are considered to be abandoned.
+	ReservedAt time.Time `json:"reserved_at,omitempty"`
+}
+
+// isAbandoned reports whether the record is a claim of a publication that has
+// not been published for too long.
+func (r record) isAbandoned(now time.Time) bool {
+	return r.PublishedAt.IsZero() && now.Sub(r.ReservedAt) > pendingRecordTTL
+}
+
+// matches reports whether the record belongs to the publication of the given
+// edition to the given destination for the given day. Records kept before
+// destinations were recorded are matched by their Slack channel.
+func (r record) matches(edition, destination, date string) bool {
+	recorded := r.Destination
+	if recorded == "" {
+		recorded = r.ChannelID
+	}
+
+	return r.Edition == edition && recorded == destination && r.Date == date
+}
+
+func newRecord(p newspaper.Publication) record {
+	return record{
+		Edition:           p.Edition,
+		Destination:       p.Destination,
+		Date:              p.Date.Format(dateLayout),
+		PublishedAt:       p.PublishedAt,
+		ChannelID:         p.ChannelID,
+		MessageTimestamps: p.MessageTimestamps,
+	}
+}
+
+func (r record) toPublication(loc *time.Location) (newspaper.Publication, error) {
+	date, err := time.ParseInLocation(dateLayout, r.Date, loc)
+	if err != nil {
+		return newspaper.Publication{}, err
+	}
+
+	return newspaper.Publication{
+		Edition:           r.Edition,
+		Destination:       r.Destination,
+		Date:              date,
+		PublishedAt:       r.PublishedAt,
+		ChannelID:         r.ChannelID,
+		MessageTimestamps: r.MessageTimestamps,
+	}, nil
+}
+
+// ReservePublication implements newspaper.Ledger interface and claims a publication
+// of the given edition to the given destination for the given day. A claim that
+// has not been followed by a publication for too long is taken over.
+func (f File) ReservePublication(edition, destination string, date time.Time,
+) (newspaper.Publication, bool, error) {
+
+	var (
+		existing newspaper.Publication
+		reserved bool
+	)
+
+	if err := f.update(func(records []record) ([]record, error) {
+		now := newspaper.TimeNowFunc()
+
+		claim := record{
+			Edition:     edition,
+			Destination: destination,
+			Date:        date.Format(dateLayout),
+			ReservedAt:  now,
+		}
+
+		for i, r := range records {
+			if !r.matches(claim.Edition, claim.Destination, claim.Date) {
+				continue
+			}
+
+			if r.isAbandoned(now) {
+				reserved = true
+				records[i] = claim
+				return records, nil
+			}
+
+			p, err := r.toPublication(date.Location())
+			if err != nil {
+				return nil, err
+			}
+			existing = p
+			return records, nil
+		}
+
+		reserved = true
+		return append(records, claim), nil
+	}); err != nil {
+		return newspaper.Publication{}, false, err
+	}
+
+	return existing, reserved, nil
+}
+
+// SavePublication implements newspaper.Ledger interface and records the given
+// publication overwriting any previous record of the same edition, destination
+// and day.
+func (f File) SavePublication(p newspaper.Publication) error {
+	return f.update(func(records []record) ([]record, error) {
+		r := newRecord(p)
+
+		for i := range records {
+			if records[i].matches(r.Edition, r.Destination, r.Date) {
+				records[i] = r
+				return records, nil
+			}
+		}
+
+		return append(records, r), nil
+	})
+}
+
+// CancelPublication implements newspaper.Ledger interface and removes a record of
+// a publication of the given edition to the given destination for the given day.
+func (f File) CancelPublication(edition, destination string, date time.Time) error {
+	return f.update(func(records []record) ([]record, error) {
+		key := date.Format(dateLayout)
+
+		var kept []record
+		for _, r := range records {
+			if r.matches(edition, destination, key) {
+				continue
+			}
+			kept = append(kept, r)
+		}
+
+		return kept, nil
+	})
+}
+
+// update reads records from the ledger's file, applies the given function to them
+// and writes the result back while holding the lock.
+func (f File) update(fn func([]record) ([]record, error)) error {
+	unlock, err := f.lock()
+	if err != nil {
+		return errors.Wrap(err, "could not lock ledger")
+	}
+	defer unlock()
+
+	records, err := f.read()
+	if err != nil {
+		return errors.Wrap(err, "could not read ledger")
+	}
+
+	records, err = fn(records)
+	if err != nil {
+		return err
+	}
+
+	if err := f.write(records); err != nil {
+		return errors.Wrap(err, "could not write ledger")
+	}
+
+	return nil
+}
+
+func (f File) read() ([]record, error) {
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var records []record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// write atomically replaces the ledger's file with the given records.
+func (f File) write(records []record) error {
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(f.path, data, 0644)
+}
+
+// lock acquires an exclusive lock on the ledger by creating a lock file holding
+// the PID of the process and the time the lock was acquired at, and returns
+// a function releasing it. A lock file older than staleLockAge is broken.
+func (f File) lock() (func(), error) {
+	path := f.path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		lf, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, err = fmt.Fprintf(lf, "%d\n%s\n", os.Getpid(), time.Now().Format(time.RFC3339Nano))
+			lf.Close()
+			if err != nil {
+				os.Remove(path)
+				return nil, err
+			}
+			return func() { os.Remove(path) }, nil
+		}
+
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if isStaleLock(path) {
+			// Breaking a lock races with other processes finding it stale at
+			// the same time, which is acceptable given how rarely locks are
+			// left behind.
+			_ = os.Remove(path)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errors.Errorf("timed out waiting for lock file %s", path)
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// isStaleLock reports whether the lock file of the given path was acquired more
+// than staleLockAge ago. Lock files without a readable time fall back to their
+// modification time.
+func isStaleLock(path string) bool {
+	acquiredAt, err := readLockTime(path)
+	if err != nil {
+		info, err := os.Stat(path)
+		if err != nil {
+			return false
+		}
+		acquiredAt = info.ModTime()
+	}
+
+	return time.Since(acquiredAt) > staleLockAge
+}
+
+// readLockTime reads the time a lock was acquired at from its lock file.
+func readLockTime(path string) (time.Time, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		return time.Time{}, errors.Errorf("malformed lock file %s", path)
+	}
+
+	if _, err := strconv.Atoi(lines[0]); err != nil {
+		return time.Time{}, errors.Wrapf(err, "malformed PID in lock file %s", path)
+	}
+
+	return time.Parse(time.RFC3339Nano, lines[1])
+}
@@ -0,0 +1,133 @@
+package matrix
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/httpjson"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+	"github.com/ztimes2/dailybugle/internal/render"
+)
+
+const formatCustomHTML = "org.matrix.custom.html"
+
+// Room provides communication with a certain Matrix room through the
+// client-server API of a homeserver.
+type Room struct {
+	client        *http.Client
+	homeserverURL string
+	accessToken   string
+	roomID        string
+	edition       string
+}
+
+// NewRoom initializes a new Room. The access token belongs to the account the
+// issues are posted on behalf of, which has to be a member of the room. The
+// edition tells apart transaction IDs of issues of different editions.
+func NewRoom(c *http.Client, homeserverURL, accessToken, roomID, edition string,
+) Room {
+
+	return Room{
+		client:        c,
+		homeserverURL: strings.TrimSuffix(homeserverURL, "/"),
+		accessToken:   accessToken,
+		roomID:        roomID,
+		edition:       edition,
+	}
+}
+
+// IsIdempotent implements newspaper.IdempotentPublisher interface. Retrying a
+// publication within the same run is safe, since the homeserver deduplicates
+// requests of the same transaction ID. It only does so per access token and for
+// a period of its own choosing though, so issues published by later runs are not
+// guaranteed to be deduplicated.
+func (r Room) IsIdempotent() bool {
+	return true
+}
+
+// Publish implements newspaper.Publisher interface and publishes the given
+// newspaper issue to the Matrix room as a single message with an HTML formatted
+// body and a plain-text fallback.
+func (r Room) Publish(issue newspaper.Issue) error {
+	formatted, err := toHTML(issue)
+	if err != nil {
+		return errors.Wrap(err, "could not render message")
+	}
+
+	// The transaction ID is derived from the edition and the day of the issue,
+	// so that the homeserver ignores the request when it happens to be retried.
+	txnID := "dailybugle-" + r.edition + "-" + newspaper.TimeNowFunc().Format("20060102")
+
+	req, err := httpjson.NewRequest(http.MethodPut,
+		r.homeserverURL+"/_matrix/client/v3/rooms/"+url.PathEscape(r.roomID)+
+			"/send/m.room.message/"+url.PathEscape(txnID),
+		messageEvent{
+			MsgType:       "m.notice",
+			Body:          toPlainText(issue),
+			Format:        formatCustomHTML,
+			FormattedBody: formatted,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+r.accessToken)
+
+	err = httpjson.Do(r.client, req, nil)
+
+	var respErr *httpjson.ResponseError
+	if errors.As(err, &respErr) {
+		var e struct {
+			ErrCode string `json:"errcode"`
+			Error   string `json:"error"`
+		}
+		if err := json.Unmarshal(respErr.Body, &e); err == nil && e.ErrCode != "" {
+			return errors.Errorf("unexpected response %s: %s: %s",
+				respErr.Status, e.ErrCode, e.Error,
+			)
+		}
+	}
+
+	return err
+}
+
+type messageEvent struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+// toPlainText renders the given issue as plain text used by clients that do not
+// support formatted messages.
+func toPlainText(issue newspaper.Issue) string {
+	var pages []string
+	for _, page := range issue {
+		pages = append(pages, render.PlainText(page))
+	}
+	return strings.Join(pages, "\n\n")
+}
+
+// contentTemplate only uses the subset of HTML that Matrix clients are
+// recommended to support.
+var contentTemplate = template.Must(template.New("content").Parse(
+	`{{range .}}<h3>{{.Headline}}</h3>
+{{range .Content}}<p>{{.}}</p>
+{{end}}<p><em>By {{.Author}}</em></p>
+{{end}}`))
+
+// toHTML renders the given issue as an HTML fragment.
+func toHTML(issue newspaper.Issue) (string, error) {
+	var b bytes.Buffer
+	if err := contentTemplate.Execute(&b, render.HTMLPages(issue)); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,130 @@
+package matrix
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+func TestRoom_Publish(t *testing.T) {
+	defer func(f func() time.Time) {
+		newspaper.TimeNowFunc = f
+	}(newspaper.TimeNowFunc)
+
+	newspaper.TimeNowFunc = func() time.Time {
+		return time.Date(2021, 2, 15, 9, 0, 0, 0, time.UTC)
+	}
+
+	var (
+		method string
+		path   string
+		auth   string
+		event  messageEvent
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		path = r.URL.EscapedPath()
+		auth = r.Header.Get("Authorization")
+
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("could not decode request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"event_id":"$event"}`))
+	}))
+	defer srv.Close()
+
+	room := NewRoom(srv.Client(), srv.URL+"/", "secret", "!room:example.com", "morning")
+
+	err := room.Publish(newspaper.Issue{
+		{
+			HeadlineText: "Front page",
+			AuthorName:   "Peter Parker",
+			ContentElements: []slack.Block{
+				slack.NewSectionBlock(slack.NewTextBlockObject(
+					slack.MarkdownType, "Hello <https://example.com|world>", false, false,
+				), nil, nil),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if method != http.MethodPut {
+		t.Errorf("expected method %s, got %s", http.MethodPut, method)
+	}
+
+	expectedPath := "/_matrix/client/v3/rooms/%21room:example.com" +
+		"/send/m.room.message/dailybugle-morning-20210215"
+	if path != expectedPath {
+		t.Errorf("expected path %s, got %s", expectedPath, path)
+	}
+
+	if auth != "Bearer secret" {
+		t.Errorf("unexpected authorization %q", auth)
+	}
+
+	if event.MsgType != "m.notice" || event.Format != formatCustomHTML {
+		t.Errorf("unexpected event %+v", event)
+	}
+	if !strings.Contains(event.Body, "Front page") ||
+		!strings.Contains(event.Body, "By Peter Parker") {
+
+		t.Errorf("unexpected plain-text body %q", event.Body)
+	}
+	if !strings.Contains(event.FormattedBody, "<h3>Front page</h3>") ||
+		!strings.Contains(event.FormattedBody, `<a href="https://example.com">world</a>`) {
+
+		t.Errorf("unexpected formatted body %q", event.FormattedBody)
+	}
+}
+
+func TestRoom_Publish_SameTransactionOnRetry(t *testing.T) {
+	var paths []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		_, _ = w.Write([]byte(`{"event_id":"$event"}`))
+	}))
+	defer srv.Close()
+
+	room := NewRoom(srv.Client(), srv.URL, "secret", "!room:example.com", "morning")
+	issue := newspaper.Issue{{HeadlineText: "Front page"}}
+
+	for i := 0; i < 2; i++ {
+		if err := room.Publish(issue); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(paths) != 2 || paths[0] != paths[1] {
+		t.Errorf("expected the same transaction ID for both attempts, got %v", paths)
+	}
+}
+
+func TestRoom_Publish_ErrorResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errcode":"M_FORBIDDEN","error":"not in room"}`))
+	}))
+	defer srv.Close()
+
+	room := NewRoom(srv.Client(), srv.URL, "secret", "!room:example.com", "morning")
+
+	err := room.Publish(newspaper.Issue{{HeadlineText: "Front page"}})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "M_FORBIDDEN: not in room") {
+		t.Errorf("unexpected error %q", err)
+	}
+}
@@ -0,0 +1,140 @@
+package mattermost
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ztimes2/dailybugle/internal/httpjson"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+	"github.com/ztimes2/dailybugle/internal/render"
+)
+
+// attachmentColor is the color of the stripe next to the attachments.
+const attachmentColor = "#C62828"
+
+// Webhook provides communication with a certain Mattermost channel through its
+// incoming webhook.
+type Webhook struct {
+	client *http.Client
+	url    string
+}
+
+// NewWebhook initializes a new Webhook.
+func NewWebhook(c *http.Client, url string) Webhook {
+	return Webhook{
+		client: c,
+		url:    url,
+	}
+}
+
+// Publish implements newspaper.Publisher interface and publishes the given
+// newspaper issue to the Mattermost channel with one message attachment per page.
+func (w Webhook) Publish(issue newspaper.Issue) error {
+	return send(w.client, w.url, "", webhookPayload{
+		Text:        toText(issue),
+		Attachments: toAttachments(issue),
+	})
+}
+
+// Channel provides communication with a certain Mattermost channel through
+// Mattermost's REST API.
+type Channel struct {
+	client    *http.Client
+	baseURL   string
+	token     string
+	channelID string
+}
+
+// NewChannel initializes a new Channel. The token is either a personal access
+// token or a bot account's token.
+func NewChannel(c *http.Client, baseURL, token, channelID string) Channel {
+	return Channel{
+		client:    c,
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		token:     token,
+		channelID: channelID,
+	}
+}
+
+// Publish implements newspaper.Publisher interface and publishes the given
+// newspaper issue to the Mattermost channel with one message attachment per page.
+func (c Channel) Publish(issue newspaper.Issue) error {
+	return send(c.client, c.baseURL+"/api/v4/posts", c.token, postPayload{
+		ChannelID: c.channelID,
+		Message:   toText(issue),
+		Props: postProps{
+			Attachments: toAttachments(issue),
+		},
+	})
+}
+
+type webhookPayload struct {
+	Text        string       `json:"text"`
+	Attachments []attachment `json:"attachments"`
+}
+
+type postPayload struct {
+	ChannelID string    `json:"channel_id"`
+	Message   string    `json:"message"`
+	Props     postProps `json:"props"`
+}
+
+type postProps struct {
+	Attachments []attachment `json:"attachments"`
+}
+
+type attachment struct {
+	Fallback string `json:"fallback"`
+	Color    string `json:"color"`
+	Title    string `json:"title"`
+	Text     string `json:"text"`
+	Footer   string `json:"footer"`
+}
+
+func send(c *http.Client, url, token string, payload interface{}) error {
+	req, err := httpjson.NewRequest(http.MethodPost, url, payload)
+	if err != nil {
+		return err
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return httpjson.Do(c, req, nil)
+}
+
+// toText returns the message's text shown above the attachments and in
+// notifications.
+func toText(issue newspaper.Issue) string {
+	var headlines []string
+	for _, page := range issue {
+		headlines = append(headlines, "**"+page.HeadlineText+"**")
+	}
+	return "#### The Daily Bugle\n" + strings.Join(headlines, " · ")
+}
+
+// toAttachments turns the given newspaper issue into message attachments where
+// each page gets its own attachment. Mattermost renders the attachments' texts as
+// Markdown, so Slack's mrkdwn gets converted to it.
+func toAttachments(issue newspaper.Issue) []attachment {
+	var attachments []attachment
+
+	for _, page := range issue {
+		var texts []string
+		for _, text := range page.ContentTexts() {
+			texts = append(texts, mrkdwn.Markdown(text))
+		}
+
+		attachments = append(attachments, attachment{
+			Fallback: render.PlainText(page),
+			Color:    attachmentColor,
+			Title:    render.Headline(page),
+			Text:     strings.Join(texts, "\n\n"),
+			Footer:   "By " + page.AuthorName,
+		})
+	}
+
+	return attachments
+}
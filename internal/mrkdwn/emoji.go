@@ -0,0 +1,64 @@
+package mrkdwn
+
+// emojiUnicodes maps names of Slack's emoji to their Unicode characters. Only the
+// emoji used by the newspaper and the most common ones are listed.
+var emojiUnicodes = map[string]string{
+	"+1":                         "👍",
+	"-1":                         "👎",
+	"alarm_clock":                "⏰",
+	"bar_chart":                  "📊",
+	"beetle":                     "🐞",
+	"bell":                       "🔔",
+	"books":                      "📚",
+	"bug":                        "🐛",
+	"calendar":                   "📆",
+	"chart_with_downwards_trend": "📉",
+	"chart_with_upwards_trend":   "📈",
+	"clipboard":                  "📋",
+	"cloud":                      "☁️",
+	"construction":               "🚧",
+	"date":                       "📅",
+	"fire":                       "🔥",
+	"hammer_and_wrench":          "🛠️",
+	"heavy_check_mark":           "✔️",
+	"hourglass":                  "⌛",
+	"hourglass_flowing_sand":     "⏳",
+	"information_source":         "ℹ️",
+	"large_green_circle":         "🟢",
+	"large_yellow_circle":        "🟡",
+	"lightning":                  "🌩️",
+	"mag":                        "🔍",
+	"memo":                       "📝",
+	"newspaper":                  "📰",
+	"no_entry":                   "⛔",
+	"package":                    "📦",
+	"pager":                      "📟",
+	"partly_sunny":               "⛅",
+	"pushpin":                    "📌",
+	"rain_cloud":                 "🌧️",
+	"red_circle":                 "🔴",
+	"rocket":                     "🚀",
+	"rotating_light":             "🚨",
+	"ship":                       "🚢",
+	"snowflake":                  "❄️",
+	"sos":                        "🆘",
+	"stopwatch":                  "⏱️",
+	"sun_behind_rain_cloud":      "🌦️",
+	"sun_small_cloud":            "🌤️",
+	"sunny":                      "☀️",
+	"tada":                       "🎉",
+	"telephone_receiver":         "📞",
+	"thunder_cloud_and_rain":     "⛈️",
+	"tornado":                    "🌪️",
+	"warning":                    "⚠️",
+	"white_check_mark":           "✅",
+	"x":                          "❌",
+	"zap":                        "⚡",
+}
+
+// EmojiUnicode returns the Unicode character of the emoji with the given name.
+// It reports false if the emoji is unknown.
+func EmojiUnicode(name string) (string, bool) {
+	u, ok := emojiUnicodes[name]
+	return u, ok
+}
@@ -1,5 +1,15 @@
 package mrkdwn
 
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
 // Bold formats the given string to appear bold according to Slack's mrkdwn format.
 func Bold(s string) string {
 	return "*" + s + "*"
@@ -17,8 +27,383 @@ func Link(s, url string) string {
 	return "<" + url + "|" + s + ">"
 }
 
+// IsLinkable reports whether the given URL coming from an external source can be
+// passed to Link. Only absolute URLs of the schemes that cannot run scripts are
+// linkable, and URLs containing characters that would end the link early are not.
+func IsLinkable(rawURL string) bool {
+	return !strings.ContainsAny(rawURL, "<|>") && isSafeURL(rawURL)
+}
+
+// Escape escapes the control characters of Slack's mrkdwn format in the given
+// string so that text coming from external sources (e.g. summaries of tickets)
+// is displayed as it is instead of being interpreted as links or mentions.
+func Escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
 // Emoji formats the given emoji name to an actual emoji according to Slack's
 // mrkdown format.
 func Emoji(name string) string {
 	return ":" + name + ":"
 }
+
+var (
+	linkRegexp       = regexp.MustCompile(`<([^<>|]+)(?:\|([^<>]*))?>`)
+	formattingRegexp = regexp.MustCompile(`([*_~])([^*_~\n]+)([*_~])`)
+)
+
+// PlainText strips Slack's mrkdwn formatting from the given string so that it can
+// be displayed where mrkdwn is not supported. Links are turned into their text
+// followed by the URL in parentheses.
+func PlainText(s string) string {
+	return replaceLinks(s, func(text, url string) string {
+		text = stripFormatting(text)
+		if text == "" || text == url {
+			return url
+		}
+		return text + " (" + url + ")"
+	}, stripFormatting)
+}
+
+// replaceLinks replaces links in the given string by the result of the link
+// function and the rest of the string by the result of the text function. Links
+// are swapped for placeholders while the text function runs so that formatting
+// wrapping a link is still recognized.
+func replaceLinks(s string, link func(text, url string) string,
+	text func(string) string) string {
+
+	var links []string
+
+	s = linkRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		sub := linkRegexp.FindStringSubmatch(m)
+
+		url, label := sub[1], sub[2]
+		if label == "" {
+			label = url
+		}
+
+		links = append(links, link(label, url))
+		return placeholder(len(links) - 1)
+	})
+
+	s = text(s)
+
+	for i, l := range links {
+		s = strings.Replace(s, placeholder(i), l, 1)
+	}
+
+	return s
+}
+
+func placeholder(i int) string {
+	return "\x00" + strconv.Itoa(i) + "\x00"
+}
+
+func stripFormatting(s string) string {
+	return unescape(replaceEmphasis(s, func(_ byte, text string) string {
+		return text
+	}))
+}
+
+// replaceEmphasis replaces bold, italic and strikethrough parts of the given
+// string by the result of the given function. Spaces at the edges of emphasized
+// parts are kept outside of them, since Markdown dialects do not recognize such
+// emphasis. Emoji are left intact even though their names may contain
+// underscores.
+func replaceEmphasis(s string, fn func(marker byte, text string) string) string {
+	var emoji []string
+
+	s = emojiRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		emoji = append(emoji, m)
+		return "\x01" + strconv.Itoa(len(emoji)-1) + "\x01"
+	})
+
+	var b strings.Builder
+
+	for {
+		loc := formattingRegexp.FindStringIndex(s)
+		if loc == nil {
+			b.WriteString(s)
+			break
+		}
+
+		m := s[loc[0]:loc[1]]
+
+		// Markers only count at word boundaries, so that underscores of
+		// identifiers like snake_case_name are left alone. The search goes on
+		// right after the rejected opening marker since it may still close
+		// a valid emphasis.
+		if m[0] != m[len(m)-1] || !isWordBoundary(s, loc[0], loc[1]) {
+			b.WriteString(s[:loc[0]+1])
+			s = s[loc[0]+1:]
+			continue
+		}
+
+		b.WriteString(s[:loc[0]])
+		s = s[loc[1]:]
+
+		inner := m[1 : len(m)-1]
+
+		text := strings.TrimSpace(inner)
+		if text == "" {
+			b.WriteString(m)
+			continue
+		}
+
+		lead := inner[:strings.Index(inner, text)]
+		trail := inner[len(lead)+len(text):]
+
+		b.WriteString(lead + fn(m[0], text) + trail)
+	}
+
+	s = b.String()
+
+	for i, e := range emoji {
+		s = strings.Replace(s, "\x01"+strconv.Itoa(i)+"\x01", e, 1)
+	}
+
+	return s
+}
+
+// isWordBoundary reports whether the part of the given string between the start
+// and end indices is neither preceded nor followed by a letter or a digit.
+func isWordBoundary(s string, start, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(s[:start]); isWordRune(r) {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(s[end:]); isWordRune(r) {
+		return false
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// unescape turns the control characters escaped according to Slack's mrkdwn
+// format back into their original form.
+func unescape(s string) string {
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(s)
+}
+
+var emojiRegexp = regexp.MustCompile(`:([a-z0-9_+\-]+):`)
+
+// Markdown converts the given string from Slack's mrkdwn format to the common
+// Markdown format. Emoji are replaced by their Unicode characters. Links with
+// URLs of schemes other than http, https and mailto are reduced to their text.
+func Markdown(s string) string {
+	return replaceLinks(s, func(text, url string) string {
+		if !isSafeURL(url) {
+			return markdownFormatting(text)
+		}
+		return "[" + markdownFormatting(text) + "](" + unescape(url) + ")"
+	}, markdownFormatting)
+}
+
+func markdownFormatting(s string) string {
+	s = replaceEmphasis(s, func(marker byte, text string) string {
+		switch marker {
+		case '*':
+			return "**" + text + "**"
+		case '~':
+			return "~~" + text + "~~"
+		default:
+			return "_" + text + "_"
+		}
+	})
+	return UnicodeEmojis(unescape(s))
+}
+
+// RocketChat converts the given string from Slack's mrkdwn format to Rocket.Chat's
+// Markdown format. Rocket.Chat shares emphasis markers with Slack, so apart from
+// links and emoji only spacing of emphasis is adjusted. Links are treated the
+// same way as by Markdown.
+func RocketChat(s string) string {
+	return replaceLinks(s, func(text, url string) string {
+		if !isSafeURL(url) {
+			return rocketChatFormatting(text)
+		}
+		return "[" + rocketChatFormatting(text) + "](" + unescape(url) + ")"
+	}, rocketChatFormatting)
+}
+
+func rocketChatFormatting(s string) string {
+	s = replaceEmphasis(s, func(marker byte, text string) string {
+		return string(marker) + text + string(marker)
+	})
+	return UnicodeEmojis(unescape(s))
+}
+
+// UnicodeEmojis replaces emoji in the given string formatted according to Slack's
+// mrkdwn format by their Unicode characters. Emoji without a known Unicode
+// character are left as they are.
+func UnicodeEmojis(s string) string {
+	return emojiRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		if u, ok := EmojiUnicode(m[1 : len(m)-1]); ok {
+			return u
+		}
+		return m
+	})
+}
+
+// Chunk splits the given string into chunks not exceeding the given number of
+// characters. The string is split on line breaks, and lines that are too long on
+// their own are split by splitLine.
+func Chunk(text string, limit int) []string {
+	var (
+		chunks []string
+		lines  []string
+		length int
+	)
+
+	flush := func() {
+		if len(lines) > 0 {
+			chunks = append(chunks, strings.Join(lines, "\n"))
+		}
+		lines = nil
+		length = 0
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if utf8.RuneCountInString(line) > limit {
+			flush()
+			parts := splitLine(line, limit)
+			chunks = append(chunks, parts[:len(parts)-1]...)
+			line = parts[len(parts)-1]
+		}
+
+		n := utf8.RuneCountInString(line)
+
+		// Accounts for the line break joining the line with the previous ones.
+		if len(lines) > 0 && length+1+n > limit {
+			flush()
+		}
+
+		if len(lines) > 0 {
+			length++
+		}
+		lines = append(lines, line)
+		length += n
+	}
+
+	flush()
+
+	return chunks
+}
+
+// splitLine splits the given line into parts not exceeding the given number of
+// characters. The line is split on whitespace outside of links and emphasized
+// text so that their formatting survives, and is only split on character
+// boundaries where there is no such whitespace.
+func splitLine(line string, limit int) []string {
+	runes := []rune(line)
+
+	protected := make([]bool, len(runes))
+	for _, re := range []*regexp.Regexp{linkRegexp, formattingRegexp} {
+		for _, m := range re.FindAllStringIndex(line, -1) {
+			start := utf8.RuneCountInString(line[:m[0]])
+			end := start + utf8.RuneCountInString(line[m[0]:m[1]])
+			for i := start; i < end; i++ {
+				protected[i] = true
+			}
+		}
+	}
+
+	var parts []string
+	start := 0
+
+	for len(runes)-start > limit {
+		cut := -1
+		for i := start + limit; i > start; i-- {
+			if unicode.IsSpace(runes[i]) && !protected[i] {
+				cut = i
+				break
+			}
+		}
+
+		if cut < 0 {
+			parts = append(parts, string(runes[start:start+limit]))
+			start += limit
+			continue
+		}
+
+		// The whitespace the line is split on is dropped.
+		parts = append(parts, string(runes[start:cut]))
+		start = cut + 1
+	}
+
+	return append(parts, string(runes[start:]))
+}
+
+// HTML converts the given string from Slack's mrkdwn format to HTML. Emoji are
+// replaced by their Unicode characters and line breaks are turned into <br>
+// elements. The text is escaped and links with URLs of schemes other than http,
+// https and mailto are reduced to their text, so the result is safe to embed
+// into HTML documents.
+func HTML(s string) string {
+	return replaceLinks(s, func(text, url string) string {
+		if !isSafeURL(url) {
+			return htmlFormatting(text)
+		}
+		return `<a href="` + html.EscapeString(unescape(url)) + `">` +
+			htmlFormatting(text) + `</a>`
+	}, htmlFormatting)
+}
+
+// isSafeURL reports whether the given URL of a link is an absolute URL of one of
+// the schemes that cannot run scripts.
+func isSafeURL(rawURL string) bool {
+	u, err := url.Parse(unescape(rawURL))
+	if err != nil {
+		return false
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto":
+		return true
+	default:
+		return false
+	}
+}
+
+func htmlFormatting(s string) string {
+	s = html.EscapeString(UnicodeEmojis(unescape(s)))
+
+	s = replaceEmphasis(s, func(marker byte, text string) string {
+		switch marker {
+		case '*':
+			return "<strong>" + text + "</strong>"
+		case '_':
+			return "<em>" + text + "</em>"
+		default:
+			return "<del>" + text + "</del>"
+		}
+	})
+
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// LinkRef represents a link found in a string formatted according to Slack's
+// mrkdwn format.
+type LinkRef struct {
+	Text string
+	URL  string
+}
+
+// Links returns links found in the given string in order of their appearance.
+func Links(s string) []LinkRef {
+	var links []LinkRef
+
+	replaceLinks(s, func(text, url string) string {
+		links = append(links, LinkRef{
+			Text: stripFormatting(text),
+			URL:  url,
+		})
+		return ""
+	}, func(s string) string {
+		return s
+	})
+
+	return links
+}
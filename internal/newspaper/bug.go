@@ -0,0 +1,253 @@
+package newspaper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize/english"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+)
+
+const (
+	// bugReportPeriod is the period covered by the Bug Report.
+	bugReportPeriod = 24 * time.Hour
+
+	// escapedBugTrendDays is the number of days escaped bugs are counted over
+	// for the trend.
+	escapedBugTrendDays = 7
+
+	noComponentName = "No component"
+	noPriorityName  = "No priority"
+)
+
+// BugTracker abstracts functionality for accessing bugs.
+type BugTracker interface {
+	GetBugsCreatedSince(time.Time) ([]Bug, error)
+	GetBugsReopenedSince(time.Time) ([]Bug, error)
+
+	// GetEscapedBugsCreatedSince returns bugs that escaped to production.
+	GetEscapedBugsCreatedSince(time.Time) ([]Bug, error)
+}
+
+// Bug represents a bug.
+type Bug struct {
+	ID         string
+	URL        string
+	Summary    string
+	Priority   string
+	Components []string
+	CreatedAt  time.Time
+}
+
+// BugReport provides functionality for writing pages for the newspaper's Bug
+// Report topic.
+type BugReport struct {
+	tracker BugTracker
+}
+
+// NewBugReport initializes a new BugReport.
+func NewBugReport(t BugTracker) BugReport {
+	return BugReport{
+		tracker: t,
+	}
+}
+
+// Write implements Writer interface and generates a page containing bugs created
+// and reopened in the past 24 hours along with the trend of escaped bugs.
+func (b BugReport) Write() (Page, error) {
+	now := TimeNowFunc()
+	since := now.Add(-bugReportPeriod)
+
+	created, err := b.tracker.GetBugsCreatedSince(since)
+	if err != nil {
+		return Page{}, errors.Wrap(err, "could not fetch new bugs")
+	}
+
+	reopened, err := b.tracker.GetBugsReopenedSince(since)
+	if err != nil {
+		return Page{}, errors.Wrap(err, "could not fetch reopened bugs")
+	}
+
+	weekAgo := now.AddDate(0, 0, -escapedBugTrendDays)
+
+	escaped, err := b.tracker.GetEscapedBugsCreatedSince(weekAgo.AddDate(0, 0, -escapedBugTrendDays))
+	if err != nil {
+		return Page{}, errors.Wrap(err, "could not fetch escaped bugs")
+	}
+
+	var thisWeek, previousWeek int
+	daily := make([]float64, escapedBugTrendDays)
+
+	for _, bug := range escaped {
+		if bug.CreatedAt.Before(weekAgo) {
+			previousWeek++
+			continue
+		}
+
+		thisWeek++
+
+		day := int(bug.CreatedAt.Sub(weekAgo).Hours() / 24)
+		if day >= escapedBugTrendDays {
+			day = escapedBugTrendDays - 1
+		}
+		daily[day]++
+	}
+
+	p := Page{
+		HeadlineEmojiName: "bug",
+		HeadlineText:      "Bug Report",
+		SummaryText: fmt.Sprintf("%s and %d reopened in the past 24 hours, %s this week.",
+			english.Plural(len(created), "new bug", "new bugs"),
+			len(reopened),
+			english.Plural(thisWeek, "escaped defect", "escaped defects"),
+		),
+		AuthorName: defaultAuthorName,
+	}
+
+	var lines []string
+
+	if len(created) == 0 {
+		lines = append(lines, "No new bugs have been spotted in the past 24 hours.")
+	} else {
+		lines = append(lines,
+			mrkdwn.Bold(english.Plural(len(created), "new bug", "new bugs"))+
+				" spotted in the past 24 hours:",
+		)
+
+		for _, group := range groupBugsByPriority(created) {
+			var links []string
+			for _, bug := range group.bugs {
+				links = append(links, mrkdwn.Link(mrkdwn.Escape(bug.ID), bug.URL))
+			}
+
+			lines = append(lines, fmt.Sprintf("    %s   %s",
+				mrkdwn.Bold(fmt.Sprintf("%s: %d", mrkdwn.Escape(group.priority), len(group.bugs))),
+				strings.Join(links, ", "),
+			))
+		}
+
+		lines = append(lines, "", "By component:   "+formatBugComponents(created))
+	}
+
+	if len(reopened) > 0 {
+		lines = append(lines, "", mrkdwn.Bold("Reopened"))
+
+		for _, bug := range reopened {
+			lines = append(lines, fmt.Sprintf("    %s %s",
+				mrkdwn.Link(mrkdwn.Escape(bug.ID), bug.URL), mrkdwn.Escape(bug.Summary),
+			))
+		}
+	}
+
+	lines = append(lines, "", fmt.Sprintf("%s   %s   %s",
+		mrkdwn.Bold("Escaped defects"),
+		toSparkline(daily),
+		formatEscapedBugTrend(thisWeek, previousWeek),
+	))
+
+	p.ContentElements = append(p.ContentElements, slack.NewSectionBlock(
+		slack.NewTextBlockObject(
+			slack.MarkdownType,
+			strings.Join(lines, "\n"),
+			false,
+			false,
+		), nil, nil,
+	))
+
+	return p, nil
+}
+
+type bugPriorityGroup struct {
+	priority string
+	bugs     []Bug
+}
+
+// groupBugsByPriority groups the given bugs by their priority from the highest
+// to the lowest one.
+func groupBugsByPriority(bugs []Bug) []bugPriorityGroup {
+	var groups []bugPriorityGroup
+	index := make(map[string]int)
+
+	for _, bug := range bugs {
+		priority := bug.Priority
+		if priority == "" {
+			priority = noPriorityName
+		}
+
+		i, ok := index[priority]
+		if !ok {
+			i = len(groups)
+			index[priority] = i
+			groups = append(groups, bugPriorityGroup{priority: priority})
+		}
+
+		groups[i].bugs = append(groups[i].bugs, bug)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		si, sj := ParseSeverity(groups[i].priority), ParseSeverity(groups[j].priority)
+		// Unknown priorities go last.
+		if si == 0 || sj == 0 {
+			return sj == 0 && si != 0
+		}
+		return si < sj
+	})
+
+	return groups
+}
+
+// formatBugComponents counts the given bugs per component from the most to the
+// least affected one.
+func formatBugComponents(bugs []Bug) string {
+	counts := make(map[string]int)
+
+	for _, bug := range bugs {
+		if len(bug.Components) == 0 {
+			counts[noComponentName]++
+			continue
+		}
+		for _, c := range bug.Components {
+			counts[c]++
+		}
+	}
+
+	var components []string
+	for c := range counts {
+		components = append(components, c)
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		if counts[components[i]] != counts[components[j]] {
+			return counts[components[i]] > counts[components[j]]
+		}
+		return components[i] < components[j]
+	})
+
+	var parts []string
+	for _, c := range components {
+		parts = append(parts, fmt.Sprintf("%s %d", mrkdwn.Escape(c), counts[c]))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// formatEscapedBugTrend describes the number of escaped bugs of this week
+// compared with the previous one.
+func formatEscapedBugTrend(thisWeek, previousWeek int) string {
+	trend := english.Plural(thisWeek, "bug", "bugs") + " in the past 7 days"
+
+	switch {
+	case thisWeek > previousWeek:
+		return trend + ", " + mrkdwn.Emoji("chart_with_upwards_trend") +
+			fmt.Sprintf(" up by %d from the week before", thisWeek-previousWeek)
+	case thisWeek < previousWeek:
+		return trend + ", " + mrkdwn.Emoji("chart_with_downwards_trend") +
+			fmt.Sprintf(" down by %d from the week before", previousWeek-thisWeek)
+	default:
+		return trend + ", same as the week before"
+	}
+}
@@ -0,0 +1,354 @@
+package newspaper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize/english"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+)
+
+const (
+	defaultPipelineRuns = 20
+	maxLongestBuilds    = 3
+)
+
+// BuildSource abstracts functionality of retrieving CI builds from any source.
+type BuildSource interface {
+	// GetDefaultBranchBuilds returns the most recent builds of the default branch
+	// of every configured repository, at most the given number per repository.
+	GetDefaultBranchBuilds(limit int) ([]Build, error)
+}
+
+// BuildStatus is a status of a CI build.
+type BuildStatus int
+
+const (
+	// BuildStatusRunning is a status of a build that has not finished yet.
+	BuildStatusRunning BuildStatus = iota
+
+	// BuildStatusPassed is a status of a build that finished successfully.
+	BuildStatusPassed
+
+	// BuildStatusFailed is a status of a build that finished unsuccessfully.
+	BuildStatusFailed
+
+	// BuildStatusCanceled is a status of a build that was canceled or skipped.
+	BuildStatusCanceled
+)
+
+// Build represents a CI build of a repository's branch.
+type Build struct {
+	Repository string
+	Branch     string
+	ID         string
+	URL        string
+	Status     BuildStatus
+	StartedAt  time.Time
+
+	// FinishedAt is zero for running builds.
+	FinishedAt time.Time
+
+	// Workflow is the name of the pipeline the build belongs to when a branch is
+	// built by several pipelines. It is empty otherwise.
+	Workflow string
+}
+
+func (b Build) isFinished() bool {
+	return b.Status == BuildStatusPassed || b.Status == BuildStatusFailed
+}
+
+func (b Build) duration() time.Duration {
+	if b.FinishedAt.IsZero() {
+		return TimeNowFunc().Sub(b.StartedAt)
+	}
+	return b.FinishedAt.Sub(b.StartedAt)
+}
+
+// PipelineHealth provides functionality for writing pages for the newspaper's
+// CI Pipeline Health topic.
+type PipelineHealth struct {
+	sources []BuildSource
+	runs    int
+}
+
+// NewPipelineHealth initializes a new PipelineHealth. Failure rates are calculated
+// over the given number of the most recent runs, which defaults to 20.
+func NewPipelineHealth(runs int, sources ...BuildSource) PipelineHealth {
+	if runs <= 0 {
+		runs = defaultPipelineRuns
+	}
+
+	return PipelineHealth{
+		sources: sources,
+		runs:    runs,
+	}
+}
+
+// pipeline represents builds of a repository's default branch sorted from the
+// newest to the oldest one.
+type pipeline struct {
+	repository string
+	branch     string
+	workflow   string
+	builds     []Build
+}
+
+func (p pipeline) name() string {
+	return pipelineName(p.repository, p.workflow)
+}
+
+// pipelineName returns the name of the pipeline of the given repository and
+// workflow. Workflows are left out of names of repositories built by a single
+// pipeline.
+func pipelineName(repository, workflow string) string {
+	if workflow == "" {
+		return repository
+	}
+	return repository + " / " + workflow
+}
+
+// lastFinishedBuild returns the most recent build that either passed or failed.
+func (p pipeline) lastFinishedBuild() (Build, bool) {
+	for _, b := range p.builds {
+		if b.isFinished() {
+			return b, true
+		}
+	}
+	return Build{}, false
+}
+
+func (p pipeline) isRed() bool {
+	b, ok := p.lastFinishedBuild()
+	return ok && b.Status == BuildStatusFailed
+}
+
+// redSince returns the time the pipeline's current streak of failed builds began.
+func (p pipeline) redSince() time.Time {
+	var since time.Time
+
+	for _, b := range p.builds {
+		if !b.isFinished() {
+			continue
+		}
+		if b.Status != BuildStatusFailed {
+			break
+		}
+		since = b.FinishedAt
+	}
+
+	return since
+}
+
+func (p pipeline) failureRate() (float64, int) {
+	var finished, failed int
+
+	for _, b := range p.builds {
+		if !b.isFinished() {
+			continue
+		}
+		finished++
+		if b.Status == BuildStatusFailed {
+			failed++
+		}
+	}
+
+	if finished == 0 {
+		return 0, 0
+	}
+
+	return float64(failed) / float64(finished), finished
+}
+
+// Write implements Writer interface and generates a page containing health of
+// CI pipelines of default branches.
+func (h PipelineHealth) Write() (Page, error) {
+	var builds []Build
+
+	for _, s := range h.sources {
+		b, err := s.GetDefaultBranchBuilds(h.runs)
+		if err != nil {
+			return Page{}, errors.Wrap(err, "could not fetch builds")
+		}
+		builds = append(builds, b...)
+	}
+
+	if len(builds) == 0 {
+		return Page{}, ErrWriterHasNoInspiration
+	}
+
+	pipelines := toPipelines(builds)
+
+	var red []pipeline
+	for _, p := range pipelines {
+		if p.isRed() {
+			red = append(red, p)
+		}
+	}
+
+	p := Page{
+		HeadlineEmojiName: "construction",
+		HeadlineText:      "CI Pipeline Health",
+		AuthorName:        defaultAuthorName,
+	}
+
+	var lines []string
+
+	if len(red) > 0 {
+		var names []string
+		for _, r := range red {
+			names = append(names, mrkdwn.Escape(r.name()))
+		}
+
+		p.SummaryText = fmt.Sprintf("%d of %s red: %s.",
+			len(red),
+			english.Plural(len(pipelines), "pipeline is", "pipelines are"),
+			strings.Join(names, ", "),
+		)
+
+		lines = append(lines,
+			mrkdwn.Emoji("rotating_light")+" "+mrkdwn.Bold("Red alert!")+
+				" The following default branches are broken:",
+		)
+
+		for _, r := range red {
+			b, _ := r.lastFinishedBuild()
+
+			lines = append(lines, fmt.Sprintf("    %s   %s   %s",
+				mrkdwn.Bold(mrkdwn.Escape(r.name())),
+				mrkdwn.Link(mrkdwn.Escape(b.ID), b.URL),
+				mrkdwn.Italic("red for "+formatDuration(TimeNowFunc().Sub(r.redSince()))),
+			))
+		}
+
+		lines = append(lines, "")
+	} else {
+		p.SummaryText = fmt.Sprintf("All %s green.",
+			english.Plural(len(pipelines), "pipeline is", "pipelines are"),
+		)
+	}
+
+	lines = append(lines, mrkdwn.Bold("Default branches"))
+
+	for _, pl := range pipelines {
+		lines = append(lines, formatPipeline(pl))
+	}
+
+	if longest := getLongestBuilds(builds, maxLongestBuilds); len(longest) > 0 {
+		lines = append(lines, "", mrkdwn.Bold("Longest builds"))
+
+		for _, b := range longest {
+			lines = append(lines, fmt.Sprintf("    %s   %s   %s",
+				mrkdwn.Escape(pipelineName(b.Repository, b.Workflow)),
+				mrkdwn.Link(mrkdwn.Escape(b.ID), b.URL),
+				mrkdwn.Italic(formatDuration(b.duration())),
+			))
+		}
+	}
+
+	p.ContentElements = append(p.ContentElements, slack.NewSectionBlock(
+		slack.NewTextBlockObject(
+			slack.MarkdownType,
+			strings.Join(lines, "\n"),
+			false,
+			false,
+		), nil, nil,
+	))
+
+	return p, nil
+}
+
+// toPipelines groups the given builds by repository, branch and workflow.
+// Pipelines are sorted by repository and workflow and their builds from the
+// newest to the oldest one.
+func toPipelines(builds []Build) []pipeline {
+	index := make(map[string]int)
+	var pipelines []pipeline
+
+	for _, b := range builds {
+		key := b.Repository + "@" + b.Branch + "/" + b.Workflow
+
+		i, ok := index[key]
+		if !ok {
+			i = len(pipelines)
+			index[key] = i
+			pipelines = append(pipelines, pipeline{
+				repository: b.Repository,
+				branch:     b.Branch,
+				workflow:   b.Workflow,
+			})
+		}
+
+		pipelines[i].builds = append(pipelines[i].builds, b)
+	}
+
+	for _, p := range pipelines {
+		sort.SliceStable(p.builds, func(i, j int) bool {
+			return p.builds[i].StartedAt.After(p.builds[j].StartedAt)
+		})
+	}
+
+	sort.SliceStable(pipelines, func(i, j int) bool {
+		if pipelines[i].repository != pipelines[j].repository {
+			return pipelines[i].repository < pipelines[j].repository
+		}
+		return pipelines[i].workflow < pipelines[j].workflow
+	})
+
+	return pipelines
+}
+
+func formatPipeline(p pipeline) string {
+	status := mrkdwn.Emoji("large_yellow_circle")
+	if b, ok := p.lastFinishedBuild(); ok {
+		if b.Status == BuildStatusFailed {
+			status = mrkdwn.Emoji("red_circle")
+		} else {
+			status = mrkdwn.Emoji("large_green_circle")
+		}
+	}
+
+	name := mrkdwn.Bold(mrkdwn.Escape(p.name()))
+	if p.branch != "" {
+		name += " (" + mrkdwn.Escape(p.branch) + ")"
+	}
+
+	latest := p.builds[0]
+
+	line := fmt.Sprintf("    %s %s   %s",
+		status, name, mrkdwn.Link(mrkdwn.Escape(latest.ID), latest.URL),
+	)
+
+	if rate, runs := p.failureRate(); runs > 0 {
+		line += "   " + mrkdwn.Italic(fmt.Sprintf("%.0f%% failed of the last %s",
+			rate*100, english.Plural(runs, "run", "runs"),
+		))
+	}
+
+	return line
+}
+
+// getLongestBuilds returns at most the given number of finished builds that took
+// the longest.
+func getLongestBuilds(builds []Build, max int) []Build {
+	var finished []Build
+	for _, b := range builds {
+		if b.isFinished() && !b.StartedAt.IsZero() {
+			finished = append(finished, b)
+		}
+	}
+
+	sort.SliceStable(finished, func(i, j int) bool {
+		return finished[i].duration() > finished[j].duration()
+	})
+
+	if len(finished) > max {
+		finished = finished[:max]
+	}
+
+	return finished
+}
@@ -34,11 +34,9 @@ func (t Ticket) daysSinceTransitionToCurrentStatus() float64 {
 	return TimeNowFunc().Sub(t.CurrentStatusSince).Hours() / 24
 }
 
-// TimeNowFunc used for mocking time.Now() from outside of the package.
-var TimeNowFunc = func() time.Time {
-	location, _ := time.LoadLocation("Asia/Singapore")
-	return time.Now().In(location)
-}
+// TimeNowFunc used for mocking time.Now() from outside of the package. It is
+// also used for setting the timezone the newspaper is written in.
+var TimeNowFunc = time.Now
 
 // CodeReviewMarket provides functionality for writing pages related to the
 // newspaper's Code Review Market topic.
@@ -73,6 +71,7 @@ func (c CodeReviewMarket) Write() (Page, error) {
 	}
 
 	if len(tickets) == 0 {
+		p.SummaryText = "No demand for code reviews today."
 		p.ContentElements = append(p.ContentElements, slack.NewSectionBlock(
 			slack.NewTextBlockObject(
 				slack.MarkdownType,
@@ -85,6 +84,13 @@ func (c CodeReviewMarket) Write() (Page, error) {
 		return p, nil
 	}
 
+	p.SummaryText = fmt.Sprintf("%s waiting for code review, the oldest one for %s.",
+		english.Plural(len(tickets), "ticket is", "tickets are"),
+		english.Plural(
+			int(tickets[0].daysSinceTransitionToCurrentStatus()), "day", "days",
+		),
+	)
+
 	lines := []string{
 		"Here is a list of hot tickets which index of waiting for code review is " +
 			"trending up. Hurry up before someone else reviews them ahead of you!",
@@ -93,7 +99,7 @@ func (c CodeReviewMarket) Write() (Page, error) {
 
 	for _, t := range tickets {
 		lines = append(lines, mrkdwn.Bold(fmt.Sprintf("   %s   +%s",
-			mrkdwn.Link(t.ID, t.URL),
+			mrkdwn.Link(mrkdwn.Escape(t.ID), t.URL),
 			english.Plural(
 				int(t.daysSinceTransitionToCurrentStatus()), "day", "days",
 			),
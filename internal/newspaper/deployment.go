@@ -0,0 +1,196 @@
+package newspaper
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize/english"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+)
+
+// deploymentLogPeriod is the period covered by the Deployment Log.
+const deploymentLogPeriod = 24 * time.Hour
+
+// DeploymentSource abstracts functionality of retrieving deployments from any
+// source.
+type DeploymentSource interface {
+	GetDeploymentsSince(time.Time) ([]Deployment, error)
+}
+
+// Deployment represents a successful deployment of a service's version to an
+// environment.
+type Deployment struct {
+	Service     string
+	Environment string
+	Version     string
+	Deployer    string
+	URL         string
+
+	// TicketIDs are IDs of Jira tickets shipped with the deployment.
+	TicketIDs []string
+
+	DeployedAt time.Time
+}
+
+var ticketIDRegexp = regexp.MustCompile(`\b[A-Z][A-Z0-9]+-[0-9]+\b`)
+
+// ParseTicketIDs finds IDs of Jira tickets (e.g. MB-123) mentioned in the given
+// text.
+func ParseTicketIDs(text string) []string {
+	var ids []string
+	seen := make(map[string]bool)
+
+	for _, id := range ticketIDRegexp.FindAllString(text, -1) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// DeploymentLog provides functionality for writing pages for the newspaper's
+// Deployment Log topic.
+type DeploymentLog struct {
+	sources       []DeploymentSource
+	ticketBaseURL string
+}
+
+// NewDeploymentLog initializes a new DeploymentLog. Tickets are linked to the
+// Jira instance of the given base URL.
+func NewDeploymentLog(jiraBaseURL string, sources ...DeploymentSource) DeploymentLog {
+	return DeploymentLog{
+		sources:       sources,
+		ticketBaseURL: strings.TrimSuffix(jiraBaseURL, "/"),
+	}
+}
+
+// Write implements Writer interface and generates a page listing deployments of
+// the past 24 hours per service and environment.
+func (d DeploymentLog) Write() (Page, error) {
+	now := TimeNowFunc()
+
+	var deployments []Deployment
+
+	for _, s := range d.sources {
+		dep, err := s.GetDeploymentsSince(now.Add(-deploymentLogPeriod))
+		if err != nil {
+			return Page{}, errors.Wrap(err, "could not fetch deployments")
+		}
+		deployments = append(deployments, dep...)
+	}
+
+	p := Page{
+		HeadlineEmojiName: "ship",
+		HeadlineText:      "Deployment Log",
+		AuthorName:        defaultAuthorName,
+	}
+
+	if len(deployments) == 0 {
+		p.SummaryText = "Nothing shipped in the past 24 hours."
+		p.ContentElements = append(p.ContentElements, slack.NewSectionBlock(
+			slack.NewTextBlockObject(
+				slack.MarkdownType,
+				"The harbour has been calm for the past 24 hours. No deployments to report.",
+				false,
+				false,
+			), nil, nil,
+		))
+		return p, nil
+	}
+
+	sort.SliceStable(deployments, func(i, j int) bool {
+		a, b := deployments[i], deployments[j]
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Environment != b.Environment {
+			return a.Environment < b.Environment
+		}
+		return a.DeployedAt.After(b.DeployedAt)
+	})
+
+	var services int
+	var lines []string
+
+	for i, dep := range deployments {
+		if i == 0 || dep.Service != deployments[i-1].Service {
+			services++
+
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, mrkdwn.Bold(mrkdwn.Escape(dep.Service)))
+		}
+
+		lines = append(lines, d.formatDeployment(dep, now.Location()))
+	}
+
+	p.SummaryText = fmt.Sprintf("%s of %s in the past 24 hours.",
+		english.Plural(len(deployments), "deployment", "deployments"),
+		english.Plural(services, "service", "services"),
+	)
+
+	p.ContentElements = append(p.ContentElements, slack.NewSectionBlock(
+		slack.NewTextBlockObject(
+			slack.MarkdownType,
+			strings.Join(lines, "\n"),
+			false,
+			false,
+		), nil, nil,
+	))
+
+	return p, nil
+}
+
+func (d DeploymentLog) formatDeployment(dep Deployment, loc *time.Location) string {
+	version := mrkdwn.Escape(dep.Version)
+	if version == "" {
+		version = "unknown version"
+	}
+	// URLs come from external sources, so the ones that cannot be linked safely
+	// are left out.
+	if mrkdwn.IsLinkable(dep.URL) {
+		version = mrkdwn.Link(version, dep.URL)
+	}
+
+	environment := mrkdwn.Escape(dep.Environment)
+	if environment == "" {
+		environment = "default"
+	}
+
+	line := fmt.Sprintf("    %s   %s", mrkdwn.Italic(environment), version)
+
+	if dep.Deployer != "" {
+		line += " by " + mrkdwn.Escape(dep.Deployer)
+	}
+
+	line += " at " + dep.DeployedAt.In(loc).Format("Mon "+time.Kitchen)
+
+	var tickets []string
+	for _, id := range dep.TicketIDs {
+		// IDs reported by deployment logs are not necessarily ticket IDs, so the
+		// ones that do not look like one are left out.
+		if ticketIDRegexp.FindString(id) != id {
+			continue
+		}
+
+		if d.ticketBaseURL == "" {
+			tickets = append(tickets, mrkdwn.Escape(id))
+			continue
+		}
+		tickets = append(tickets, mrkdwn.Link(mrkdwn.Escape(id), d.ticketBaseURL+"/browse/"+id))
+	}
+
+	if len(tickets) > 0 {
+		line += "   " + strings.Join(tickets, ", ")
+	}
+
+	return line
+}
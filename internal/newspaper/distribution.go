@@ -0,0 +1,229 @@
+package newspaper
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultMaxAttempts = 1
+	defaultRetryDelay  = 2 * time.Second
+)
+
+// Destination represents a named publisher an issue of the newspaper is
+// distributed to.
+type Destination struct {
+	Name      string
+	Publisher Publisher
+
+	// MaxAttempts is the number of times publishing is attempted before giving up.
+	// Defaults to a single attempt. Only publishers implementing
+	// IdempotentPublisher interface are given more than one attempt.
+	MaxAttempts int
+
+	// RetryDelay is the delay before the first retry. It doubles with every
+	// following retry. Defaults to 2 seconds.
+	RetryDelay time.Duration
+
+	// Ledger, when set, keeps records of issues of the Edition published to the
+	// destination, so that an issue is published to it at most once a day even
+	// if the newspaper is run several times.
+	Ledger  Ledger
+	Edition string
+}
+
+// DeliveryStatus is a status of a delivery of an issue to a destination.
+type DeliveryStatus int
+
+const (
+	// DeliveryStatusFailed is a status of a delivery that failed after all its
+	// attempts.
+	DeliveryStatusFailed DeliveryStatus = iota
+
+	// DeliveryStatusPublished is a status of a delivery that was published.
+	DeliveryStatusPublished
+
+	// DeliveryStatusSkipped is a status of a delivery that was skipped because
+	// the issue had already been published to the destination.
+	DeliveryStatusSkipped
+)
+
+// String returns a human-readable representation of the status.
+func (s DeliveryStatus) String() string {
+	switch s {
+	case DeliveryStatusPublished:
+		return "published"
+	case DeliveryStatusSkipped:
+		return "skipped"
+	default:
+		return "failed"
+	}
+}
+
+// Delivery represents a result of distributing an issue to a destination.
+type Delivery struct {
+	Destination string
+	Status      DeliveryStatus
+	Attempts    int
+	Err         error
+}
+
+// Deliveries represents results of distributing an issue to all destinations.
+type Deliveries []Delivery
+
+// Err returns an error describing all failed deliveries or nil if there are none.
+func (d Deliveries) Err() error {
+	var failures []string
+
+	for _, delivery := range d {
+		if delivery.Status == DeliveryStatusFailed {
+			failures = append(failures, fmt.Sprintf("%s: %v",
+				delivery.Destination, delivery.Err,
+			))
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("could not publish to %d of %d destinations: %s",
+		len(failures), len(d), strings.Join(failures, "; "),
+	)
+}
+
+// EditAndDistribute prepares and edits an issue of the newspaper containing pages
+// supplied by the writers and distributes it to all the destinations.
+func EditAndDistribute(destinations []Destination, writers ...Writer,
+) (Deliveries, error) {
+
+	issue, err := Edit(writers...)
+	if err != nil {
+		return nil, err
+	}
+
+	return Distribute(issue, destinations...), nil
+}
+
+// Distribute publishes the given issue to all the destinations concurrently.
+// Destinations are independent of each other, so a failure of one of them
+// does not prevent the issue from being published to the rest. Results are
+// returned in the same order as the destinations.
+func Distribute(issue Issue, destinations ...Destination) Deliveries {
+	deliveries := make(Deliveries, len(destinations))
+
+	var wg sync.WaitGroup
+
+	for i, d := range destinations {
+		wg.Add(1)
+
+		go func(i int, d Destination) {
+			defer wg.Done()
+			deliveries[i] = deliver(issue, d)
+		}(i, d)
+	}
+
+	wg.Wait()
+
+	return deliveries
+}
+
+// deliver publishes the given issue to the destination unless the destination's
+// ledger has a record of it.
+func deliver(issue Issue, d Destination) Delivery {
+	if d.Ledger == nil {
+		return attempt(issue, d)
+	}
+
+	date := TimeNowFunc()
+
+	_, reserved, err := d.Ledger.ReservePublication(d.Edition, d.Name, date)
+	if err != nil {
+		return Delivery{
+			Destination: d.Name,
+			Status:      DeliveryStatusFailed,
+			Err:         errors.Wrap(err, "could not reserve publication"),
+		}
+	}
+
+	if !reserved {
+		return Delivery{
+			Destination: d.Name,
+			Status:      DeliveryStatusSkipped,
+		}
+	}
+
+	delivery := attempt(issue, d)
+
+	if delivery.Status != DeliveryStatusPublished {
+		// Releases the claim so that the next run is able to publish the issue.
+		_ = d.Ledger.CancelPublication(d.Edition, d.Name, date)
+		return delivery
+	}
+
+	if err := d.Ledger.SavePublication(Publication{
+		Edition:     d.Edition,
+		Destination: d.Name,
+		Date:        date,
+		PublishedAt: TimeNowFunc(),
+	}); err != nil {
+		delivery.Status = DeliveryStatusFailed
+		delivery.Err = errors.Wrap(err, "could not save publication")
+	}
+
+	return delivery
+}
+
+// attempt publishes the given issue to the destination retrying failed attempts
+// of idempotent publishers.
+func attempt(issue Issue, d Destination) Delivery {
+	maxAttempts := defaultMaxAttempts
+	if isIdempotent(d.Publisher) && d.MaxAttempts > 0 {
+		maxAttempts = d.MaxAttempts
+	}
+
+	delay := d.RetryDelay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+
+	delivery := Delivery{
+		Destination: d.Name,
+	}
+
+	for delivery.Attempts < maxAttempts {
+		if delivery.Attempts > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		delivery.Attempts++
+
+		err := d.Publisher.Publish(issue)
+		if err == nil {
+			delivery.Status = DeliveryStatusPublished
+			delivery.Err = nil
+			return delivery
+		}
+
+		if errors.Is(err, ErrIssueAlreadyPublished) {
+			delivery.Status = DeliveryStatusSkipped
+			delivery.Err = nil
+			return delivery
+		}
+
+		delivery.Status = DeliveryStatusFailed
+		delivery.Err = err
+	}
+
+	return delivery
+}
+
+func isIdempotent(p Publisher) bool {
+	ip, ok := p.(IdempotentPublisher)
+	return ok && ip.IsIdempotent()
+}
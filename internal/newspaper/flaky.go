@@ -0,0 +1,240 @@
+package newspaper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize/english"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+)
+
+const (
+	defaultFlakyTestWindow = 14 * 24 * time.Hour
+	maxFlakyTests          = 10
+)
+
+// TestResultSource abstracts functionality of retrieving results of automated
+// tests from any source.
+type TestResultSource interface {
+	GetTestResultsSince(time.Time) ([]TestResult, error)
+}
+
+// TestResultStore abstracts functionality of keeping history of results of
+// automated tests.
+type TestResultStore interface {
+	TestResultSource
+
+	// SaveTestResults adds the given results to the history ignoring the ones
+	// that are already there.
+	SaveTestResults([]TestResult) error
+}
+
+// TestResult represents a result of a single run of an automated test.
+type TestResult struct {
+	Suite  string
+	Name   string
+	Commit string
+	Passed bool
+	RanAt  time.Time
+}
+
+// FlakyTestReport provides functionality for writing pages for the newspaper's
+// Flaky Test Report topic.
+type FlakyTestReport struct {
+	sources []TestResultSource
+	store   TestResultStore
+	window  time.Duration
+}
+
+// NewFlakyTestReport initializes a new FlakyTestReport. Results fetched from the
+// sources are kept in the store, so the report covers the given rolling window
+// even when the sources no longer have older results. The window defaults to
+// 14 days.
+func NewFlakyTestReport(window time.Duration, store TestResultStore,
+	sources ...TestResultSource) FlakyTestReport {
+
+	if window <= 0 {
+		window = defaultFlakyTestWindow
+	}
+
+	return FlakyTestReport{
+		sources: sources,
+		store:   store,
+		window:  window,
+	}
+}
+
+// testStats represents results of a test within the report's window.
+type testStats struct {
+	suite         string
+	name          string
+	runs          int
+	failures      int
+	flakyCommits  int
+	lastFailureAt time.Time
+}
+
+func (s testStats) failureRate() float64 {
+	return float64(s.failures) / float64(s.runs)
+}
+
+// Write implements Writer interface and generates a page listing tests that both
+// passed and failed on the same commit within the report's window.
+func (f FlakyTestReport) Write() (Page, error) {
+	since := TimeNowFunc().Add(-f.window)
+
+	for _, s := range f.sources {
+		results, err := s.GetTestResultsSince(since)
+		if err != nil {
+			return Page{}, errors.Wrap(err, "could not fetch test results")
+		}
+
+		if err := f.store.SaveTestResults(results); err != nil {
+			return Page{}, errors.Wrap(err, "could not save test results")
+		}
+	}
+
+	results, err := f.store.GetTestResultsSince(since)
+	if err != nil {
+		return Page{}, errors.Wrap(err, "could not read test results")
+	}
+
+	if len(results) == 0 {
+		return Page{}, ErrWriterHasNoInspiration
+	}
+
+	flaky := getFlakyTests(results)
+
+	days := english.Plural(int(f.window.Hours()/24), "day", "days")
+
+	p := Page{
+		HeadlineEmojiName: "beetle",
+		HeadlineText:      "Flaky Test Report",
+		AuthorName:        defaultAuthorName,
+	}
+
+	var lines []string
+
+	if len(flaky) == 0 {
+		p.SummaryText = fmt.Sprintf("No flaky tests in the past %s.", days)
+		lines = append(lines, fmt.Sprintf(
+			"Every test kept its word on every commit in the past %s. Keep it up!", days,
+		))
+	} else {
+		p.SummaryText = fmt.Sprintf("%s in the past %s.",
+			english.Plural(len(flaky), "flaky test", "flaky tests"), days,
+		)
+		lines = append(lines, fmt.Sprintf(
+			"These tests both passed and failed on the same commit in the past %s:",
+			days,
+		))
+
+		if len(flaky) > maxFlakyTests {
+			flaky = flaky[:maxFlakyTests]
+		}
+
+		for _, s := range flaky {
+			name := mrkdwn.Bold(mrkdwn.Escape(s.name))
+			if s.suite != "" {
+				name += " (" + mrkdwn.Escape(s.suite) + ")"
+			}
+
+			lines = append(lines, fmt.Sprintf("    %s   %s", name, mrkdwn.Italic(fmt.Sprintf(
+				"%.0f%% failed of %s, flaky on %s",
+				s.failureRate()*100,
+				english.Plural(s.runs, "run", "runs"),
+				english.Plural(s.flakyCommits, "commit", "commits"),
+			))))
+		}
+	}
+
+	p.ContentElements = append(p.ContentElements, slack.NewSectionBlock(
+		slack.NewTextBlockObject(
+			slack.MarkdownType,
+			strings.Join(lines, "\n"),
+			false,
+			false,
+		), nil, nil,
+	))
+
+	return p, nil
+}
+
+// getFlakyTests returns statistics of tests that both passed and failed on at
+// least one commit sorted from the flakiest to the least flaky one.
+func getFlakyTests(results []TestResult) []testStats {
+	type outcomes struct {
+		passed, failed bool
+	}
+
+	stats := make(map[string]*testStats)
+	commits := make(map[string]map[string]*outcomes)
+
+	for _, r := range results {
+		key := r.Suite + "\x00" + r.Name
+
+		s, ok := stats[key]
+		if !ok {
+			s = &testStats{suite: r.Suite, name: r.Name}
+			stats[key] = s
+			commits[key] = make(map[string]*outcomes)
+		}
+
+		s.runs++
+		if !r.Passed {
+			s.failures++
+			if r.RanAt.After(s.lastFailureAt) {
+				s.lastFailureAt = r.RanAt
+			}
+		}
+
+		if r.Commit == "" {
+			continue
+		}
+
+		o, ok := commits[key][r.Commit]
+		if !ok {
+			o = &outcomes{}
+			commits[key][r.Commit] = o
+		}
+
+		if r.Passed {
+			o.passed = true
+		} else {
+			o.failed = true
+		}
+	}
+
+	var flaky []testStats
+
+	for key, s := range stats {
+		for _, o := range commits[key] {
+			if o.passed && o.failed {
+				s.flakyCommits++
+			}
+		}
+
+		if s.flakyCommits > 0 {
+			flaky = append(flaky, *s)
+		}
+	}
+
+	sort.Slice(flaky, func(i, j int) bool {
+		if flaky[i].failureRate() != flaky[j].failureRate() {
+			return flaky[i].failureRate() > flaky[j].failureRate()
+		}
+		if flaky[i].flakyCommits != flaky[j].flakyCommits {
+			return flaky[i].flakyCommits > flaky[j].flakyCommits
+		}
+		if !flaky[i].lastFailureAt.Equal(flaky[j].lastFailureAt) {
+			return flaky[i].lastFailureAt.After(flaky[j].lastFailureAt)
+		}
+		return flaky[i].name < flaky[j].name
+	})
+
+	return flaky
+}
@@ -0,0 +1,302 @@
+package newspaper
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize/english"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+)
+
+// incidentDigestPeriod is the period covered by the Incident Digest.
+const incidentDigestPeriod = 24 * time.Hour
+
+// IncidentSource abstracts functionality of retrieving incidents from any source.
+type IncidentSource interface {
+	// GetIncidentsSince returns incidents that were opened or resolved since the
+	// given time, as well as incidents that are still ongoing.
+	GetIncidentsSince(time.Time) ([]Incident, error)
+}
+
+// Incident represents an incident.
+type Incident struct {
+	ID    string
+	URL   string
+	Title string
+
+	// Severity is the incident's severity where 1 is the most severe one and 0
+	// means that the severity is unknown.
+	Severity int
+
+	OpenedAt time.Time
+
+	// ResolvedAt is zero for ongoing incidents.
+	ResolvedAt time.Time
+}
+
+// IsOngoing reports whether the incident has not been resolved yet.
+func (i Incident) IsOngoing() bool {
+	return i.ResolvedAt.IsZero()
+}
+
+func (i Incident) duration() time.Duration {
+	if i.IsOngoing() {
+		return TimeNowFunc().Sub(i.OpenedAt)
+	}
+	return i.ResolvedAt.Sub(i.OpenedAt)
+}
+
+var severityRegexp = regexp.MustCompile(`(?i)^(?:sev|p|s)?\s*-?\s*([0-9])\b`)
+
+// ParseSeverity turns a severity or a priority label (e.g. "SEV1", "P2",
+// "Highest") into the severity used by Incident. It returns 0 for labels that
+// are not recognized.
+func ParseSeverity(label string) int {
+	label = strings.TrimSpace(label)
+
+	if m := severityRegexp.FindStringSubmatch(label); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		// Some teams use SEV0 for the most severe incidents.
+		if n == 0 {
+			return 1
+		}
+		return n
+	}
+
+	switch strings.ToLower(label) {
+	case "highest", "blocker", "critical":
+		return 1
+	case "high", "major":
+		return 2
+	case "medium", "moderate":
+		return 3
+	case "low", "minor":
+		return 4
+	case "lowest", "trivial":
+		return 5
+	default:
+		return 0
+	}
+}
+
+// IncidentDigest provides functionality for writing pages for the newspaper's
+// Incident Digest topic.
+type IncidentDigest struct {
+	sources []IncidentSource
+}
+
+// NewIncidentDigest initializes a new IncidentDigest.
+func NewIncidentDigest(sources ...IncidentSource) IncidentDigest {
+	return IncidentDigest{
+		sources: sources,
+	}
+}
+
+// Write implements Writer interface and generates a page summarizing incidents
+// opened, resolved and still ongoing during the past 24 hours.
+func (d IncidentDigest) Write() (Page, error) {
+	since := TimeNowFunc().Add(-incidentDigestPeriod)
+
+	incidents, err := getIncidentsSince(d.sources, since)
+	if err != nil {
+		return Page{}, err
+	}
+
+	var opened, resolved, ongoing []Incident
+
+	for _, i := range incidents {
+		switch {
+		case i.IsOngoing():
+			ongoing = append(ongoing, i)
+		case !i.ResolvedAt.Before(since):
+			resolved = append(resolved, i)
+		}
+
+		if !i.OpenedAt.Before(since) {
+			opened = append(opened, i)
+		}
+	}
+
+	p := Page{
+		HeadlineEmojiName: "rotating_light",
+		HeadlineText:      "Incident Digest",
+		AuthorName:        defaultAuthorName,
+	}
+
+	if len(opened) == 0 && len(resolved) == 0 && len(ongoing) == 0 {
+		p.SummaryText = "No incidents in the past 24 hours."
+		p.ContentElements = append(p.ContentElements, slack.NewSectionBlock(
+			slack.NewTextBlockObject(
+				slack.MarkdownType,
+				"The city has been quiet for the past 24 hours. No incidents to report.",
+				false,
+				false,
+			), nil, nil,
+		))
+		return p, nil
+	}
+
+	p.SummaryText = fmt.Sprintf("%s opened, %d resolved, %d ongoing.",
+		english.Plural(len(opened), "incident", "incidents"),
+		len(resolved),
+		len(ongoing),
+	)
+
+	var lines []string
+
+	sections := []struct {
+		title     string
+		incidents []Incident
+	}{
+		{"Still ongoing", ongoing},
+		{"Opened in the past 24 hours", opened},
+		{"Resolved in the past 24 hours", resolved},
+	}
+
+	for _, s := range sections {
+		if len(s.incidents) == 0 {
+			continue
+		}
+
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, mrkdwn.Bold(s.title))
+
+		for _, i := range s.incidents {
+			lines = append(lines, formatIncident(i))
+		}
+	}
+
+	p.ContentElements = append(p.ContentElements, slack.NewSectionBlock(
+		slack.NewTextBlockObject(
+			slack.MarkdownType,
+			strings.Join(lines, "\n"),
+			false,
+			false,
+		), nil, nil,
+	))
+
+	return p, nil
+}
+
+func formatIncident(i Incident) string {
+	severity := "SEV?"
+	if i.Severity > 0 {
+		severity = "SEV" + strconv.Itoa(i.Severity)
+	}
+
+	duration := formatDuration(i.duration())
+	if i.IsOngoing() {
+		duration += " and counting"
+	}
+
+	return fmt.Sprintf("    %s   %s %s   %s",
+		mrkdwn.Bold(severity),
+		mrkdwn.Link(mrkdwn.Escape(i.ID), i.URL),
+		mrkdwn.Escape(i.Title),
+		mrkdwn.Italic(duration),
+	)
+}
+
+// formatDuration formats the given duration in hours and minutes (e.g. 3h 25m).
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+// IncidentCache implements IncidentSource interface and fetches incidents from
+// the sources only once, so that several writers are able to share them. At
+// least incidents of the past 24 hours are fetched, while a request reaching
+// further into the past fetches them again.
+type IncidentCache struct {
+	sources []IncidentSource
+
+	mu        sync.Mutex
+	fetched   bool
+	since     time.Time
+	incidents []Incident
+}
+
+// NewIncidentCache initializes a new IncidentCache.
+func NewIncidentCache(sources ...IncidentSource) *IncidentCache {
+	return &IncidentCache{
+		sources: sources,
+	}
+}
+
+// GetIncidentsSince implements IncidentSource interface.
+func (c *IncidentCache) GetIncidentsSince(t time.Time) ([]Incident, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.fetched || t.Before(c.since) {
+		since := TimeNowFunc().Add(-incidentDigestPeriod)
+		if t.Before(since) {
+			since = t
+		}
+
+		var incidents []Incident
+		for _, s := range c.sources {
+			i, err := s.GetIncidentsSince(since)
+			if err != nil {
+				return nil, err
+			}
+			incidents = append(incidents, i...)
+		}
+
+		c.fetched, c.since, c.incidents = true, since, incidents
+	}
+
+	var incidents []Incident
+
+	for _, i := range c.incidents {
+		if i.IsOngoing() || !i.OpenedAt.Before(t) || !i.ResolvedAt.Before(t) {
+			incidents = append(incidents, i)
+		}
+	}
+
+	return incidents, nil
+}
+
+// getIncidentsSince fetches incidents from all the sources sorted by severity
+// and by the time they were opened.
+func getIncidentsSince(sources []IncidentSource, since time.Time) ([]Incident, error) {
+	var incidents []Incident
+
+	for _, s := range sources {
+		i, err := s.GetIncidentsSince(since)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not fetch incidents")
+		}
+		incidents = append(incidents, i...)
+	}
+
+	sort.SliceStable(incidents, func(i, j int) bool {
+		si, sj := incidents[i].Severity, incidents[j].Severity
+		if si != sj {
+			// Incidents of unknown severity go last.
+			if si == 0 || sj == 0 {
+				return sj == 0
+			}
+			return si < sj
+		}
+		return incidents[i].OpenedAt.Before(incidents[j].OpenedAt)
+	})
+
+	return incidents, nil
+}
@@ -0,0 +1,291 @@
+package newspaper
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize/english"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+)
+
+// flowMetricsPeriod is the period flow metrics are compared over.
+const flowMetricsPeriod = 7 * 24 * time.Hour
+
+// TicketHistorian abstracts functionality for accessing history of tickets.
+type TicketHistorian interface {
+	GetTicketsCompletedSince(time.Time) ([]CompletedTicket, error)
+}
+
+// CompletedTicket represents a completed ticket along with the history of its
+// workflow.
+type CompletedTicket struct {
+	ID          string
+	URL         string
+	CreatedAt   time.Time
+	CompletedAt time.Time
+
+	// StartedAt is the time work on the ticket started. It is zero for tickets
+	// that were completed without ever being worked on.
+	StartedAt time.Time
+
+	// TimeInStatus is the time the ticket spent in each workflow status before
+	// it was completed.
+	TimeInStatus map[string]time.Duration
+}
+
+// LeadTime returns the time it took to complete the ticket since it was created.
+func (t CompletedTicket) LeadTime() time.Duration {
+	return t.CompletedAt.Sub(t.CreatedAt)
+}
+
+// CycleTime returns the time it took to complete the ticket since work on it
+// started.
+func (t CompletedTicket) CycleTime() (time.Duration, bool) {
+	if t.StartedAt.IsZero() {
+		return 0, false
+	}
+	return t.CompletedAt.Sub(t.StartedAt), true
+}
+
+// FlowMetrics provides functionality for writing pages for the newspaper's Flow
+// Metrics topic.
+type FlowMetrics struct {
+	historian TicketHistorian
+	weekday   time.Weekday
+}
+
+// NewFlowMetrics initializes a new FlowMetrics. Metrics are weekly, so pages are
+// written only on the given day of the week.
+func NewFlowMetrics(h TicketHistorian, weekday time.Weekday) FlowMetrics {
+	return FlowMetrics{
+		historian: h,
+		weekday:   weekday,
+	}
+}
+
+// durationStats represents the median and the 85th percentile of durations.
+type durationStats struct {
+	count  int
+	median time.Duration
+	p85    time.Duration
+}
+
+func newDurationStats(durations []time.Duration) durationStats {
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+
+	return durationStats{
+		count:  len(durations),
+		median: getPercentile(durations, 50),
+		p85:    getPercentile(durations, 85),
+	}
+}
+
+// getPercentile returns the given percentile of the sorted durations using the
+// nearest-rank method.
+func getPercentile(sorted []time.Duration, percentile float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+// weeklyMetrics represents flow metrics of tickets completed within a week.
+type weeklyMetrics struct {
+	tickets      int
+	leadTime     durationStats
+	cycleTime    durationStats
+	timeInStatus map[string]durationStats
+}
+
+func newWeeklyMetrics(tickets []CompletedTicket) weeklyMetrics {
+	var leadTimes, cycleTimes []time.Duration
+	timesInStatus := make(map[string][]time.Duration)
+
+	for _, t := range tickets {
+		leadTimes = append(leadTimes, t.LeadTime())
+
+		if d, ok := t.CycleTime(); ok {
+			cycleTimes = append(cycleTimes, d)
+		}
+
+		for status, d := range t.TimeInStatus {
+			timesInStatus[status] = append(timesInStatus[status], d)
+		}
+	}
+
+	m := weeklyMetrics{
+		tickets:      len(tickets),
+		leadTime:     newDurationStats(leadTimes),
+		cycleTime:    newDurationStats(cycleTimes),
+		timeInStatus: make(map[string]durationStats),
+	}
+
+	for status, d := range timesInStatus {
+		m.timeInStatus[status] = newDurationStats(d)
+	}
+
+	return m
+}
+
+// Write implements Writer interface and generates a page containing lead time,
+// cycle time and time in status of tickets completed during the past week
+// compared with the week before.
+func (f FlowMetrics) Write() (Page, error) {
+	now := TimeNowFunc()
+
+	if now.Weekday() != f.weekday {
+		return Page{}, ErrWriterHasNoInspiration
+	}
+
+	weekAgo := now.Add(-flowMetricsPeriod)
+
+	tickets, err := f.historian.GetTicketsCompletedSince(weekAgo.Add(-flowMetricsPeriod))
+	if err != nil {
+		return Page{}, errors.Wrap(err, "could not fetch completed tickets")
+	}
+
+	var thisWeek, previousWeek []CompletedTicket
+	for _, t := range tickets {
+		if t.CompletedAt.Before(weekAgo) {
+			previousWeek = append(previousWeek, t)
+		} else {
+			thisWeek = append(thisWeek, t)
+		}
+	}
+
+	if len(thisWeek) == 0 {
+		return Page{}, ErrWriterHasNoInspiration
+	}
+
+	current := newWeeklyMetrics(thisWeek)
+	previous := newWeeklyMetrics(previousWeek)
+
+	p := Page{
+		HeadlineEmojiName: "stopwatch",
+		HeadlineText:      "Flow Metrics",
+		AuthorName:        defaultAuthorName,
+	}
+
+	switch delta, ok := getDelta(current.cycleTime.median, previous.cycleTime.median); {
+	case current.cycleTime.count == 0:
+		p.SummaryText = fmt.Sprintf("%s completed in the past 7 days.",
+			english.Plural(current.tickets, "ticket", "tickets"),
+		)
+	case ok:
+		p.SummaryText = fmt.Sprintf("Median cycle time is %s, %s from the week before.",
+			formatMetricDuration(current.cycleTime.median), delta,
+		)
+	default:
+		p.SummaryText = fmt.Sprintf("Median cycle time is %s.",
+			formatMetricDuration(current.cycleTime.median),
+		)
+	}
+
+	lines := []string{
+		fmt.Sprintf("%s completed in the past 7 days (%d the week before).",
+			mrkdwn.Bold(english.Plural(current.tickets, "ticket", "tickets")),
+			previous.tickets,
+		),
+		"",
+		formatDurationStats("Lead time", current.leadTime, previous.leadTime),
+		formatDurationStats("Cycle time", current.cycleTime, previous.cycleTime),
+	}
+
+	var statuses []string
+	for status := range current.timeInStatus {
+		statuses = append(statuses, status)
+	}
+
+	// Statuses tickets spend the most time in go first.
+	sort.Slice(statuses, func(i, j int) bool {
+		a, b := current.timeInStatus[statuses[i]], current.timeInStatus[statuses[j]]
+		if a.median != b.median {
+			return a.median > b.median
+		}
+		return statuses[i] < statuses[j]
+	})
+
+	if len(statuses) > 0 {
+		lines = append(lines, "", mrkdwn.Bold("Time in status"))
+
+		for _, status := range statuses {
+			lines = append(lines, "    "+formatDurationStats(
+				mrkdwn.Escape(status), current.timeInStatus[status], previous.timeInStatus[status],
+			))
+		}
+	}
+
+	p.ContentElements = append(p.ContentElements, slack.NewSectionBlock(
+		slack.NewTextBlockObject(
+			slack.MarkdownType,
+			strings.Join(lines, "\n"),
+			false,
+			false,
+		), nil, nil,
+	))
+
+	return p, nil
+}
+
+func formatDurationStats(name string, current, previous durationStats) string {
+	if current.count == 0 {
+		return fmt.Sprintf("%s   %s", mrkdwn.Bold(name), mrkdwn.Italic("no data"))
+	}
+
+	line := fmt.Sprintf("%s   median %s   p85 %s",
+		mrkdwn.Bold(name),
+		formatMetricDuration(current.median),
+		formatMetricDuration(current.p85),
+	)
+
+	if delta, ok := getDelta(current.median, previous.median); ok {
+		line += "   " + mrkdwn.Italic(delta+" week over week")
+	}
+
+	return line
+}
+
+// getDelta describes the change of the given duration compared with the previous
+// one. It returns false if there is nothing to compare with.
+func getDelta(current, previous time.Duration) (string, bool) {
+	if previous == 0 {
+		return "", false
+	}
+
+	change := (float64(current) - float64(previous)) / float64(previous) * 100
+
+	switch {
+	case math.Round(change) > 0:
+		return fmt.Sprintf("up %.0f%%", change), true
+	case math.Round(change) < 0:
+		return fmt.Sprintf("down %.0f%%", -change), true
+	default:
+		return "unchanged", true
+	}
+}
+
+// formatMetricDuration formats the given duration in days, hours or minutes
+// depending on its length (e.g. 2.5d, 7.2h or 45m).
+func formatMetricDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%.1fd", d.Hours()/24)
+	case d >= time.Hour:
+		return fmt.Sprintf("%.1fh", d.Hours())
+	default:
+		return fmt.Sprintf("%.0fm", d.Minutes())
+	}
+}
@@ -2,13 +2,14 @@ package newspaper
 
 import (
 	"errors"
+	"time"
 
 	"github.com/slack-go/slack"
 )
 
-// EditAndPublish prepares and edits an issue of the newspaper containing pages
-// supplied by the writers and publishes it to the publisher.
-func EditAndPublish(p Publisher, writers ...Writer) error {
+// Edit prepares and edits an issue of the newspaper containing pages supplied by
+// the writers.
+func Edit(writers ...Writer) (Issue, error) {
 	var issue Issue
 
 	// IDEA the following loop can be rewritten to leverage concurrency
@@ -18,13 +19,13 @@ func EditAndPublish(p Publisher, writers ...Writer) error {
 			if errors.Is(err, ErrWriterHasNoInspiration) {
 				continue
 			}
-			return err
+			return nil, err
 		}
 
 		issue = append(issue, page)
 	}
 
-	return p.Publish(issue)
+	return issue, nil
 }
 
 // ErrWriterHasNoInspiration is used to differentiate a case when a writer has
@@ -36,6 +37,59 @@ type Publisher interface {
 	Publish(Issue) error
 }
 
+// IdempotentPublisher is implemented by publishers that can be given the same
+// issue more than once without publishing it twice, for example because they
+// replace what they published before. Only such publishers are retried after
+// a failed attempt, since a failed attempt may still have published a part of
+// the issue.
+type IdempotentPublisher interface {
+	Publisher
+	IsIdempotent() bool
+}
+
+// ErrIssueAlreadyPublished is used to differentiate a case when a publisher skips
+// an issue because the same edition has already been published on the same day.
+var ErrIssueAlreadyPublished = errors.New("issue has already been published")
+
+// ErrPublicationInProgress is used when an issue cannot be published because
+// another run has claimed the publication of the same edition on the same day
+// and has not finished it yet.
+var ErrPublicationInProgress = errors.New("publication of the issue is in progress")
+
+// Ledger abstracts functionality for keeping records of published issues so that
+// the same edition does not get published to the same destination more than once
+// a day.
+type Ledger interface {
+	// ReservePublication claims a publication of the given edition to the given
+	// destination for the given day. If the publication has already been claimed,
+	// then its record is returned along with false.
+	ReservePublication(edition, destination string, date time.Time,
+	) (Publication, bool, error)
+
+	// SavePublication records the given publication.
+	SavePublication(Publication) error
+
+	// CancelPublication removes a claim of a publication of the given edition to
+	// the given destination for the given day so that it can be published again.
+	CancelPublication(edition, destination string, date time.Time) error
+}
+
+// Publication represents a record of an issue published to a certain destination.
+type Publication struct {
+	Edition           string
+	Destination       string
+	Date              time.Time
+	PublishedAt       time.Time
+	ChannelID         string
+	MessageTimestamps []string
+}
+
+// IsPending reports whether the publication has been claimed but has not been
+// published yet.
+func (p Publication) IsPending() bool {
+	return p.PublishedAt.IsZero()
+}
+
 // Issue represents a collection of pages that form an issue of the newspaper.
 type Issue []Page
 
@@ -49,6 +103,37 @@ type Writer interface {
 type Page struct {
 	HeadlineEmojiName string
 	HeadlineText      string
+	SummaryText       string
 	AuthorName        string
 	ContentElements   []slack.Block
 }
+
+// ContentTexts returns texts of the page's content elements formatted according
+// to Slack's mrkdwn format. Elements that do not carry any text are omitted.
+func (p Page) ContentTexts() []string {
+	var texts []string
+
+	for _, e := range p.ContentElements {
+		switch b := e.(type) {
+		case *slack.SectionBlock:
+			if b.Text != nil {
+				texts = append(texts, b.Text.Text)
+			}
+			for _, f := range b.Fields {
+				texts = append(texts, f.Text)
+			}
+		case *slack.HeaderBlock:
+			if b.Text != nil {
+				texts = append(texts, b.Text.Text)
+			}
+		case *slack.ContextBlock:
+			for _, el := range b.ContextElements.Elements {
+				if t, ok := el.(*slack.TextBlockObject); ok {
+					texts = append(texts, t.Text)
+				}
+			}
+		}
+	}
+
+	return texts
+}
@@ -0,0 +1,208 @@
+package newspaper
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+)
+
+// OnCallSchedule abstracts functionality of retrieving on-call shifts from any
+// source.
+type OnCallSchedule interface {
+	GetOnCallShiftsByDay(time.Time) ([]OnCallShift, error)
+}
+
+// OnCallShift represents a shift of a person being on call for an escalation
+// policy.
+type OnCallShift struct {
+	EscalationPolicy string
+	Level            int
+	PersonName       string
+
+	// StartsAt is zero for shifts that have always been there.
+	StartsAt time.Time
+
+	// EndsAt is zero for shifts that never end.
+	EndsAt time.Time
+}
+
+// OnCallRoster provides functionality for writing pages for the newspaper's
+// On-Call Roster topic.
+type OnCallRoster struct {
+	schedules []OnCallSchedule
+	fallbacks []OnCallSchedule
+}
+
+// NewOnCallRoster initializes a new OnCallRoster.
+func NewOnCallRoster(schedules ...OnCallSchedule) OnCallRoster {
+	return OnCallRoster{
+		schedules: schedules,
+	}
+}
+
+// WithFallback returns a copy of the OnCallRoster that fetches shifts from the
+// given schedules when its own schedules fail.
+func (o OnCallRoster) WithFallback(schedules ...OnCallSchedule) OnCallRoster {
+	o.fallbacks = schedules
+	return o
+}
+
+// Write implements Writer interface and generates a page containing today's
+// primary and secondary on-call people per escalation policy.
+func (o OnCallRoster) Write() (Page, error) {
+	now := TimeNowFunc()
+
+	shifts, err := getOnCallShiftsByDay(o.schedules, now)
+	if err != nil {
+		if len(o.fallbacks) == 0 {
+			return Page{}, err
+		}
+
+		log.Printf("%v, falling back to other on-call schedules", err)
+
+		if shifts, err = getOnCallShiftsByDay(o.fallbacks, now); err != nil {
+			return Page{}, err
+		}
+	}
+
+	if len(shifts) == 0 {
+		return Page{}, ErrWriterHasNoInspiration
+	}
+
+	byPolicy := make(map[string][]OnCallShift)
+	var policies []string
+
+	for _, s := range shifts {
+		if _, ok := byPolicy[s.EscalationPolicy]; !ok {
+			policies = append(policies, s.EscalationPolicy)
+		}
+		byPolicy[s.EscalationPolicy] = append(byPolicy[s.EscalationPolicy], s)
+	}
+
+	sort.Strings(policies)
+
+	p := Page{
+		HeadlineEmojiName: "pager",
+		HeadlineText:      "On-Call Roster",
+		AuthorName:        defaultAuthorName,
+	}
+
+	lines := []string{
+		fmt.Sprintf("Here is who is keeping the city safe today (%s):",
+			now.Location().String(),
+		),
+	}
+
+	var primaries []string
+
+	for _, policy := range policies {
+		policyShifts := byPolicy[policy]
+
+		sort.SliceStable(policyShifts, func(i, j int) bool {
+			if policyShifts[i].Level != policyShifts[j].Level {
+				return policyShifts[i].Level < policyShifts[j].Level
+			}
+			return policyShifts[i].StartsAt.Before(policyShifts[j].StartsAt)
+		})
+
+		lines = append(lines, "", mrkdwn.Bold(mrkdwn.Escape(policy)))
+
+		for _, s := range policyShifts {
+			if s.Level <= 1 && isOnCallAt(s, now) {
+				primaries = append(primaries, mrkdwn.Escape(s.PersonName))
+			}
+
+			line := fmt.Sprintf("    %s: %s",
+				getOnCallLevelName(s.Level),
+				mrkdwn.Bold(mrkdwn.Escape(s.PersonName)),
+			)
+			if times := o.formatShiftTimes(s); times != "" {
+				line += "   " + times
+			}
+
+			lines = append(lines, line)
+		}
+	}
+
+	if len(primaries) > 0 {
+		p.SummaryText = fmt.Sprintf("%s on call as primary right now.",
+			strings.Join(primaries, ", "),
+		)
+	} else {
+		p.SummaryText = "Nobody is on call as primary right now."
+	}
+
+	p.ContentElements = append(p.ContentElements, slack.NewSectionBlock(
+		slack.NewTextBlockObject(
+			slack.MarkdownType,
+			strings.Join(lines, "\n"),
+			false,
+			false,
+		), nil, nil,
+	))
+
+	return p, nil
+}
+
+func getOnCallShiftsByDay(schedules []OnCallSchedule, t time.Time,
+) ([]OnCallShift, error) {
+
+	var shifts []OnCallShift
+
+	for _, s := range schedules {
+		sh, err := s.GetOnCallShiftsByDay(t)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not fetch on-call shifts")
+		}
+		shifts = append(shifts, sh...)
+	}
+
+	return shifts, nil
+}
+
+func isOnCallAt(s OnCallShift, t time.Time) bool {
+	return !s.StartsAt.After(t) && (s.EndsAt.IsZero() || s.EndsAt.After(t))
+}
+
+func getOnCallLevelName(level int) string {
+	switch level {
+	case 0, 1:
+		return "Primary"
+	case 2:
+		return "Secondary"
+	default:
+		return fmt.Sprintf("Level %d", level)
+	}
+}
+
+// formatShiftTimes describes when the given shift hands off in the location of
+// the current time. Times that do not fall on the current day are shown with a
+// weekday and shifts that neither start nor end are described without any times.
+func (o OnCallRoster) formatShiftTimes(s OnCallShift) string {
+	now := TimeNowFunc()
+
+	format := func(t time.Time) string {
+		t = t.In(now.Location())
+		if t.Year() == now.Year() && t.YearDay() == now.YearDay() {
+			return t.Format(time.Kitchen)
+		}
+		return t.Format("Mon " + time.Kitchen)
+	}
+
+	switch {
+	case s.StartsAt.IsZero() && s.EndsAt.IsZero():
+		return ""
+	case s.StartsAt.IsZero():
+		return mrkdwn.Italic("until " + format(s.EndsAt))
+	case s.EndsAt.IsZero():
+		return mrkdwn.Italic("since " + format(s.StartsAt))
+	default:
+		return mrkdwn.Italic(format(s.StartsAt) + " - " + format(s.EndsAt))
+	}
+}
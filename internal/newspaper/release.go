@@ -2,6 +2,7 @@ package newspaper
 
 import (
 	"fmt"
+	"log"
 	"sort"
 	"strings"
 	"time"
@@ -45,7 +46,8 @@ const (
 // ReleaseForecast provides functionality for writing pages for the newspaper's
 // Release Forecast topic.
 type ReleaseForecast struct {
-	calendars []Calendar
+	calendars       []Calendar
+	incidentSources []IncidentSource
 }
 
 // NewReleaseForecast initializes a new ReleaseForecast.
@@ -55,9 +57,32 @@ func NewReleaseForecast(calendars ...Calendar) ReleaseForecast {
 	}
 }
 
+// WithIncidents returns a copy of the ReleaseForecast that takes ongoing incidents
+// from the given sources into account. The forecast is written without incidents
+// when they cannot be fetched.
+func (r ReleaseForecast) WithIncidents(sources ...IncidentSource) ReleaseForecast {
+	r.incidentSources = sources
+	return r
+}
+
 // Write implements Writer interface and generates a page containing latest
 // information related to the newspaper's Release Forecast topic.
 func (r ReleaseForecast) Write() (Page, error) {
+	var majorIncidents []Incident
+
+	if len(r.incidentSources) > 0 {
+		incidents, err := getIncidentsSince(r.incidentSources, TimeNowFunc())
+		if err != nil {
+			log.Printf("release forecast is written without incidents: %v", err)
+		}
+
+		for _, i := range incidents {
+			if i.IsOngoing() && i.Severity == 1 {
+				majorIncidents = append(majorIncidents, i)
+			}
+		}
+	}
+
 	var events []CalendarEvent
 
 	for _, c := range r.calendars {
@@ -84,12 +109,29 @@ func (r ReleaseForecast) Write() (Page, error) {
 	p := Page{
 		HeadlineEmojiName: "sun_behind_rain_cloud",
 		HeadlineText:      "Release Forecast",
-		AuthorName:        defaultAuthorName,
+		SummaryText: getReleaseForecastSummary(
+			majorIncidents, pushNotifications, campaigns, codeFreezes,
+		),
+		AuthorName: defaultAuthorName,
 	}
 
 	lines := []string{
-		getReleaseForecastSummary(pushNotifications, campaigns, codeFreezes) + " " +
-			getReleaseForecastRecommendation(pushNotifications, campaigns, codeFreezes),
+		p.SummaryText + " " + getReleaseForecastRecommendation(
+			majorIncidents, pushNotifications, campaigns, codeFreezes,
+		),
+	}
+
+	if len(majorIncidents) > 0 {
+		breakdown := []string{
+			"",
+			"Tornado warning is in effect until the following incidents are resolved:",
+		}
+
+		for _, i := range majorIncidents {
+			breakdown = append(breakdown, formatIncident(i))
+		}
+
+		lines = append(lines, breakdown...)
 	}
 
 	if len(codeFreezes) > 0 {
@@ -149,8 +191,13 @@ func (r ReleaseForecast) Write() (Page, error) {
 	return p, nil
 }
 
-func getReleaseForecastSummary(pushNotifications, campaigns, codeFreezes []CalendarEvent,
-) string {
+func getReleaseForecastSummary(majorIncidents []Incident,
+	pushNotifications, campaigns, codeFreezes []CalendarEvent) string {
+
+	if len(majorIncidents) > 0 {
+		return mrkdwn.Emoji("tornado") +
+			" The day is stormy due to an ongoing SEV1 incident."
+	}
 
 	if len(codeFreezes) > 0 {
 		return mrkdwn.Emoji("snowflake") +
@@ -175,9 +222,12 @@ func getReleaseForecastSummary(pushNotifications, campaigns, codeFreezes []Calen
 	return mrkdwn.Emoji("sunny") + " The day is sunny and the sky is clear."
 }
 
-func getReleaseForecastRecommendation(
-	pushNotifications, campaigns, codeFreezes []CalendarEvent,
-) string {
+func getReleaseForecastRecommendation(majorIncidents []Incident,
+	pushNotifications, campaigns, codeFreezes []CalendarEvent) string {
+
+	if len(majorIncidents) > 0 {
+		return "Hold off any releases until the storm is over!"
+	}
 
 	if len(codeFreezes) > 0 {
 		return "Totally bad day for a release!"
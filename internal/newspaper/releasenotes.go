@@ -0,0 +1,267 @@
+package newspaper
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize/english"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+)
+
+// ReleaseHistory abstracts functionality of retrieving releases of a codebase
+// from any source.
+type ReleaseHistory interface {
+	// GetNextRelease returns commits made since the latest release that are
+	// going into the next one.
+	GetNextRelease() (Release, error)
+}
+
+// Release represents a release of a codebase.
+type Release struct {
+	// PreviousTag is the tag of the release before this one. It is empty for the
+	// very first release.
+	PreviousTag string
+
+	// Commits are commits made since the previous release sorted from the newest
+	// to the oldest one.
+	Commits []Commit
+}
+
+// Commit represents a commit of a codebase.
+type Commit struct {
+	Hash        string
+	Message     string
+	AuthorName  string
+	CommittedAt time.Time
+}
+
+// conventionalCommitRegexp matches subjects of commits following Conventional
+// Commits, e.g. "feat(api)!: drop support of v1".
+var conventionalCommitRegexp = regexp.MustCompile(
+	`^([a-zA-Z]+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`,
+)
+
+// conventionalCommit represents a commit parsed according to Conventional Commits.
+type conventionalCommit struct {
+	Type        string
+	Scope       string
+	Description string
+	Breaking    bool
+
+	// Body holds everything after the subject line.
+	Body string
+}
+
+func parseConventionalCommit(message string) conventionalCommit {
+	message = strings.TrimSpace(message)
+
+	subject, body := message, ""
+	if i := strings.Index(message, "\n"); i >= 0 {
+		subject, body = message[:i], strings.TrimSpace(message[i+1:])
+	}
+
+	c := conventionalCommit{
+		Description: strings.TrimSpace(subject),
+		Body:        body,
+		Breaking: strings.Contains(body, "BREAKING CHANGE:") ||
+			strings.Contains(body, "BREAKING-CHANGE:"),
+	}
+
+	if m := conventionalCommitRegexp.FindStringSubmatch(c.Description); m != nil {
+		c.Type = strings.ToLower(m[1])
+		c.Scope = m[2]
+		c.Breaking = c.Breaking || m[3] != ""
+		c.Description = m[4]
+	}
+
+	return c
+}
+
+// releaseNoteSections lists sections of release notes in the order of their
+// appearance along with the commit types they include.
+var releaseNoteSections = []struct {
+	title string
+	types []string
+}{
+	{"Features", []string{"feat"}},
+	{"Bug Fixes", []string{"fix"}},
+	{"Performance Improvements", []string{"perf"}},
+	{"Reverts", []string{"revert"}},
+	{"Refactoring", []string{"refactor"}},
+	{"Documentation", []string{"docs"}},
+	{"Tests", []string{"test"}},
+	{"Build and CI", []string{"build", "ci"}},
+	{"Chores", []string{"chore", "style"}},
+}
+
+// ReleaseNotes provides functionality for writing pages for the newspaper's
+// Release Notes topic.
+type ReleaseNotes struct {
+	history        ReleaseHistory
+	ticketBaseURL  string
+	ticketIDRegexp *regexp.Regexp
+}
+
+// NewReleaseNotes initializes a new ReleaseNotes. Tickets of the given Jira
+// projects mentioned in commit messages are linked to the Jira instance of the
+// given base URL. When no projects are given, anything that looks like a ticket
+// ID is linked, including names such as UTF-8 that only look like one.
+func NewReleaseNotes(jiraBaseURL string, projectKeys []string,
+	history ReleaseHistory) ReleaseNotes {
+
+	r := ReleaseNotes{
+		history:        history,
+		ticketBaseURL:  strings.TrimSuffix(jiraBaseURL, "/"),
+		ticketIDRegexp: ticketIDRegexp,
+	}
+
+	if len(projectKeys) > 0 {
+		var keys []string
+		for _, k := range projectKeys {
+			keys = append(keys, regexp.QuoteMeta(k))
+		}
+
+		r.ticketIDRegexp = regexp.MustCompile(
+			`\b(?:` + strings.Join(keys, "|") + `)-[0-9]+\b`,
+		)
+	}
+
+	return r
+}
+
+// Write implements Writer interface and generates a page containing commits going
+// into the next release grouped by their Conventional Commits type.
+func (r ReleaseNotes) Write() (Page, error) {
+	release, err := r.history.GetNextRelease()
+	if err != nil {
+		return Page{}, errors.Wrap(err, "could not fetch next release")
+	}
+
+	if len(release.Commits) == 0 {
+		return Page{}, ErrWriterHasNoInspiration
+	}
+
+	p := Page{
+		HeadlineEmojiName: "package",
+		HeadlineText:      "Release Notes",
+		AuthorName:        defaultAuthorName,
+	}
+
+	if release.PreviousTag == "" {
+		p.SummaryText = fmt.Sprintf("The very first release is going to ship %s.",
+			english.Plural(len(release.Commits), "commit", "commits"),
+		)
+	} else {
+		p.SummaryText = fmt.Sprintf("The next release is going to ship %s since %s.",
+			english.Plural(len(release.Commits), "commit", "commits"),
+			mrkdwn.Escape(release.PreviousTag),
+		)
+	}
+
+	var (
+		breaking []string
+		types    []string
+	)
+	grouped := make(map[string][]string)
+
+	for _, c := range release.Commits {
+		cc := parseConventionalCommit(c.Message)
+
+		line := "    • " + r.formatCommit(cc)
+
+		if cc.Breaking {
+			breaking = append(breaking, line)
+		}
+
+		if _, ok := grouped[cc.Type]; !ok {
+			types = append(types, cc.Type)
+		}
+		grouped[cc.Type] = append(grouped[cc.Type], line)
+	}
+
+	lines := []string{p.SummaryText}
+
+	if len(breaking) > 0 {
+		lines = append(lines, "", mrkdwn.Emoji("warning")+" "+mrkdwn.Bold("Breaking Changes"))
+		lines = append(lines, breaking...)
+	}
+
+	for _, s := range releaseNoteSections {
+		var section []string
+		for _, t := range s.types {
+			section = append(section, grouped[t]...)
+			delete(grouped, t)
+		}
+
+		if len(section) == 0 {
+			continue
+		}
+
+		lines = append(lines, "", mrkdwn.Bold(s.title))
+		lines = append(lines, section...)
+	}
+
+	// Commits of unknown types and the ones not following Conventional Commits
+	// go last.
+	var other []string
+	for _, t := range types {
+		other = append(other, grouped[t]...)
+	}
+
+	if len(other) > 0 {
+		lines = append(lines, "", mrkdwn.Bold("Other Changes"))
+		lines = append(lines, other...)
+	}
+
+	p.ContentElements = append(p.ContentElements, slack.NewSectionBlock(
+		slack.NewTextBlockObject(
+			slack.MarkdownType,
+			strings.Join(lines, "\n"),
+			false,
+			false,
+		), nil, nil,
+	))
+
+	return p, nil
+}
+
+// formatCommit formats the given commit linking tickets mentioned in its subject
+// and appending the ones mentioned only in its body.
+func (r ReleaseNotes) formatCommit(c conventionalCommit) string {
+	// Ticket IDs are left intact by escaping, so they are still found in the
+	// escaped description.
+	line := r.ticketIDRegexp.ReplaceAllStringFunc(
+		mrkdwn.Escape(c.Description), r.toTicketLink,
+	)
+
+	if c.Scope != "" {
+		line = mrkdwn.Bold(mrkdwn.Escape(c.Scope)+":") + " " + line
+	}
+
+	var extra []string
+	seen := make(map[string]bool)
+	for _, id := range r.ticketIDRegexp.FindAllString(c.Body, -1) {
+		if seen[id] || strings.Contains(c.Description, id) {
+			continue
+		}
+		seen[id] = true
+		extra = append(extra, r.toTicketLink(id))
+	}
+
+	if len(extra) > 0 {
+		line += "   " + strings.Join(extra, ", ")
+	}
+
+	return line
+}
+
+func (r ReleaseNotes) toTicketLink(id string) string {
+	if r.ticketBaseURL == "" {
+		return id
+	}
+	return mrkdwn.Link(id, r.ticketBaseURL+"/browse/"+id)
+}
@@ -0,0 +1,235 @@
+package newspaper
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize/english"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+)
+
+// sparklineBars are bars of a sparkline from the lowest to the highest one.
+var sparklineBars = []rune("▁▂▃▄▅▆▇█")
+
+// SprintBoard abstracts functionality of retrieving sprints from any agile board.
+type SprintBoard interface {
+	// GetActiveSprint returns the board's active sprint. It returns false if no
+	// sprint is active.
+	GetActiveSprint() (Sprint, bool, error)
+}
+
+// Sprint represents a sprint of an agile board.
+type Sprint struct {
+	Name     string
+	Goal     string
+	StartsAt time.Time
+	EndsAt   time.Time
+	Tickets  []SprintTicket
+}
+
+// SprintTicket represents a ticket planned for a sprint.
+type SprintTicket struct {
+	ID          string
+	StoryPoints float64
+
+	// AddedAt is zero for tickets that have been in the sprint since it started.
+	AddedAt time.Time
+
+	// CompletedAt is zero for tickets that have not been completed yet.
+	CompletedAt time.Time
+}
+
+func (t SprintTicket) isAddedMidSprint() bool {
+	return !t.AddedAt.IsZero()
+}
+
+func (t SprintTicket) isCompletedBy(tm time.Time) bool {
+	return !t.CompletedAt.IsZero() && !t.CompletedAt.After(tm)
+}
+
+// SprintProgress provides functionality for writing pages for the newspaper's
+// Sprint Progress topic.
+type SprintProgress struct {
+	board SprintBoard
+}
+
+// NewSprintProgress initializes a new SprintProgress.
+func NewSprintProgress(board SprintBoard) SprintProgress {
+	return SprintProgress{
+		board: board,
+	}
+}
+
+// Write implements Writer interface and generates a page containing progress of
+// the active sprint.
+func (s SprintProgress) Write() (Page, error) {
+	sprint, ok, err := s.board.GetActiveSprint()
+	if err != nil {
+		return Page{}, errors.Wrap(err, "could not fetch active sprint")
+	}
+
+	if !ok {
+		return Page{}, ErrWriterHasNoInspiration
+	}
+
+	now := TimeNowFunc()
+
+	var committed, added, completed float64
+	var addedTickets int
+
+	for _, t := range sprint.Tickets {
+		if t.isAddedMidSprint() {
+			added += t.StoryPoints
+			addedTickets++
+		} else {
+			committed += t.StoryPoints
+		}
+
+		if t.isCompletedBy(now) {
+			completed += t.StoryPoints
+		}
+	}
+
+	daysLeft := getDaysLeft(now, sprint.EndsAt)
+
+	p := Page{
+		HeadlineEmojiName: "bar_chart",
+		HeadlineText:      "Sprint Progress",
+		SummaryText: fmt.Sprintf("%s: %s of %s story points done, %s left.",
+			mrkdwn.Escape(sprint.Name),
+			formatStoryPoints(completed),
+			formatStoryPoints(committed+added),
+			english.Plural(daysLeft, "day", "days"),
+		),
+		AuthorName: defaultAuthorName,
+	}
+
+	var lines []string
+
+	if sprint.Goal != "" {
+		for _, l := range strings.Split(strings.TrimSpace(sprint.Goal), "\n") {
+			lines = append(lines, "> "+mrkdwn.Italic(mrkdwn.Escape(strings.TrimSpace(l))))
+		}
+		lines = append(lines, "")
+	}
+
+	lines = append(lines,
+		fmt.Sprintf("%s ends in %s (%s).",
+			mrkdwn.Bold(mrkdwn.Escape(sprint.Name)),
+			english.Plural(daysLeft, "day", "days"),
+			sprint.EndsAt.In(now.Location()).Format("Mon 2 Jan"),
+		),
+		"",
+		fmt.Sprintf("    Committed: %s story points", mrkdwn.Bold(formatStoryPoints(committed))),
+		fmt.Sprintf("    Completed: %s story points (%.0f%%)",
+			mrkdwn.Bold(formatStoryPoints(completed)),
+			getPercentage(completed, committed+added),
+		),
+	)
+
+	if addedTickets > 0 {
+		lines = append(lines, fmt.Sprintf("    Added mid-sprint: %s story points across %s",
+			mrkdwn.Bold(formatStoryPoints(added)),
+			english.Plural(addedTickets, "ticket", "tickets"),
+		))
+	}
+
+	if burndown := getBurndown(sprint, now); len(burndown) > 1 {
+		lines = append(lines, "", fmt.Sprintf("Burndown:   %s   %s",
+			toSparkline(burndown),
+			mrkdwn.Italic(formatStoryPoints(burndown[len(burndown)-1])+" story points remaining"),
+		))
+	}
+
+	p.ContentElements = append(p.ContentElements, slack.NewSectionBlock(
+		slack.NewTextBlockObject(
+			slack.MarkdownType,
+			strings.Join(lines, "\n"),
+			false,
+			false,
+		), nil, nil,
+	))
+
+	return p, nil
+}
+
+// getDaysLeft returns the number of calendar days left until the given end of a
+// sprint.
+func getDaysLeft(now, endsAt time.Time) int {
+	endsAt = endsAt.In(now.Location())
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	endDay := time.Date(endsAt.Year(), endsAt.Month(), endsAt.Day(), 0, 0, 0, 0, now.Location())
+
+	days := int(math.Round(endDay.Sub(today).Hours() / 24))
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// getBurndown returns story points remaining at the end of every calendar day of
+// the sprint up to the given time. Days start at midnight in the location of the
+// given time.
+func getBurndown(sprint Sprint, now time.Time) []float64 {
+	var burndown []float64
+
+	startsAt := sprint.StartsAt.In(now.Location())
+	firstDay := time.Date(
+		startsAt.Year(), startsAt.Month(), startsAt.Day(), 0, 0, 0, 0, now.Location(),
+	)
+
+	for day := firstDay; day.Before(now) && !day.After(sprint.EndsAt); day = day.AddDate(0, 0, 1) {
+		endOfDay := day.AddDate(0, 0, 1)
+		if endOfDay.After(now) {
+			endOfDay = now
+		}
+
+		var remaining float64
+		for _, t := range sprint.Tickets {
+			if t.AddedAt.After(endOfDay) || t.isCompletedBy(endOfDay) {
+				continue
+			}
+			remaining += t.StoryPoints
+		}
+
+		burndown = append(burndown, remaining)
+	}
+
+	return burndown
+}
+
+// toSparkline draws the given values as a sparkline scaled to the highest value.
+func toSparkline(values []float64) string {
+	var max float64
+	for _, v := range values {
+		max = math.Max(max, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if max > 0 {
+			i = int(math.Round(v / max * float64(len(sparklineBars)-1)))
+		}
+		b.WriteRune(sparklineBars[i])
+	}
+
+	return b.String()
+}
+
+func getPercentage(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return part / whole * 100
+}
+
+// formatStoryPoints formats the given story points without insignificant decimals.
+func formatStoryPoints(points float64) string {
+	return fmt.Sprintf("%g", math.Round(points*10)/10)
+}
@@ -0,0 +1,174 @@
+package newspaper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize/english"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+)
+
+const defaultStaleTicketThreshold = 5 * 24 * time.Hour
+
+// StuckTicketer abstracts functionality for accessing tickets that may be stuck.
+type StuckTicketer interface {
+	GetTicketsInProgress() ([]Ticket, error)
+	GetFlaggedTickets() ([]Ticket, error)
+	GetBlockedTickets() ([]BlockedTicket, error)
+}
+
+// BlockedTicket represents a ticket blocked by other unresolved tickets.
+type BlockedTicket struct {
+	Ticket
+	BlockedBy []Ticket
+}
+
+// TrafficReport provides functionality for writing pages for the newspaper's
+// Traffic Report topic.
+type TrafficReport struct {
+	ticketer  StuckTicketer
+	threshold time.Duration
+}
+
+// NewTrafficReport initializes a new TrafficReport. Tickets that have been in
+// progress longer than the given threshold are reported as stale. The threshold
+// defaults to 5 days.
+func NewTrafficReport(t StuckTicketer, threshold time.Duration) TrafficReport {
+	if threshold <= 0 {
+		threshold = defaultStaleTicketThreshold
+	}
+
+	return TrafficReport{
+		ticketer:  t,
+		threshold: threshold,
+	}
+}
+
+// Write implements Writer interface and generates a page containing tickets that
+// are stale, flagged or blocked.
+func (r TrafficReport) Write() (Page, error) {
+	inProgress, err := r.ticketer.GetTicketsInProgress()
+	if err != nil {
+		return Page{}, errors.Wrap(err, "could not fetch tickets in progress")
+	}
+
+	flagged, err := r.ticketer.GetFlaggedTickets()
+	if err != nil {
+		return Page{}, errors.Wrap(err, "could not fetch flagged tickets")
+	}
+
+	blocked, err := r.ticketer.GetBlockedTickets()
+	if err != nil {
+		return Page{}, errors.Wrap(err, "could not fetch blocked tickets")
+	}
+
+	var stale []Ticket
+	for _, t := range inProgress {
+		// Tickets without a known time of entering their status cannot be
+		// told to be stale.
+		if t.CurrentStatusSince.IsZero() {
+			continue
+		}
+
+		if TimeNowFunc().Sub(t.CurrentStatusSince) > r.threshold {
+			stale = append(stale, t)
+		}
+	}
+
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].CurrentStatusSince.Before(stale[j].CurrentStatusSince)
+	})
+
+	p := Page{
+		HeadlineEmojiName: "no_entry",
+		HeadlineText:      "Traffic Report",
+		AuthorName:        defaultAuthorName,
+	}
+
+	if len(stale) == 0 && len(flagged) == 0 && len(blocked) == 0 {
+		p.SummaryText = "No traffic jams today."
+		p.ContentElements = append(p.ContentElements, slack.NewSectionBlock(
+			slack.NewTextBlockObject(
+				slack.MarkdownType,
+				"Traffic is flowing smoothly today. No stale, flagged or blocked tickets.",
+				false,
+				false,
+			), nil, nil,
+		))
+		return p, nil
+	}
+
+	p.SummaryText = fmt.Sprintf("%d stale, %d flagged and %d blocked %s.",
+		len(stale), len(flagged), len(blocked),
+		english.PluralWord(len(stale)+len(flagged)+len(blocked), "ticket", "tickets"),
+	)
+
+	var lines []string
+
+	if len(stale) > 0 {
+		lines = append(lines, mrkdwn.Bold(fmt.Sprintf(
+			"Stuck in progress for more than %s",
+			english.Plural(int(r.threshold.Hours()/24), "day", "days"),
+		)))
+
+		for _, t := range stale {
+			lines = append(lines, fmt.Sprintf("    %s %s   %s",
+				mrkdwn.Link(mrkdwn.Escape(t.ID), t.URL),
+				mrkdwn.Escape(t.Summary),
+				mrkdwn.Italic(english.Plural(
+					int(t.daysSinceTransitionToCurrentStatus()), "day", "days",
+				)),
+			))
+		}
+	}
+
+	if len(flagged) > 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, mrkdwn.Bold("Flagged as impediments"))
+
+		for _, t := range flagged {
+			lines = append(lines, fmt.Sprintf("    %s %s   %s",
+				mrkdwn.Link(mrkdwn.Escape(t.ID), t.URL),
+				mrkdwn.Escape(t.Summary),
+				mrkdwn.Italic(mrkdwn.Escape(t.CurrentStatus)),
+			))
+		}
+	}
+
+	if len(blocked) > 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, mrkdwn.Bold("Blocked by unresolved tickets"))
+
+		for _, t := range blocked {
+			var blockers []string
+			for _, b := range t.BlockedBy {
+				blockers = append(blockers, mrkdwn.Link(mrkdwn.Escape(b.ID), b.URL))
+			}
+
+			lines = append(lines, fmt.Sprintf("    %s %s   blocked by %s",
+				mrkdwn.Link(mrkdwn.Escape(t.ID), t.URL),
+				mrkdwn.Escape(t.Summary),
+				strings.Join(blockers, ", "),
+			))
+		}
+	}
+
+	p.ContentElements = append(p.ContentElements, slack.NewSectionBlock(
+		slack.NewTextBlockObject(
+			slack.MarkdownType,
+			strings.Join(lines, "\n"),
+			false,
+			false,
+		), nil, nil,
+	))
+
+	return p, nil
+}
@@ -0,0 +1,220 @@
+package pagerduty
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/httpjson"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+const (
+	defaultBaseURL = "https://api.pagerduty.com"
+	pageSize       = 100
+)
+
+// PagerDuty provides communication with PagerDuty REST API.
+type PagerDuty struct {
+	client              *http.Client
+	baseURL             string
+	apiToken            string
+	escalationPolicyIDs []string
+}
+
+// Config holds PagerDuty's configuration.
+type Config struct {
+	// BaseURL defaults to PagerDuty's public API.
+	BaseURL  string
+	APIToken string
+
+	// EscalationPolicyIDs limits on-call shifts to the given escalation policies.
+	// Shifts of all escalation policies are fetched when empty.
+	EscalationPolicyIDs []string
+}
+
+// New initializes a new PagerDuty.
+func New(c *http.Client, conf Config) PagerDuty {
+	if conf.BaseURL == "" {
+		conf.BaseURL = defaultBaseURL
+	}
+
+	return PagerDuty{
+		client:              c,
+		baseURL:             strings.TrimSuffix(conf.BaseURL, "/"),
+		apiToken:            conf.APIToken,
+		escalationPolicyIDs: conf.EscalationPolicyIDs,
+	}
+}
+
+type oncallsResponse struct {
+	Oncalls []struct {
+		EscalationPolicy reference `json:"escalation_policy"`
+		EscalationLevel  int       `json:"escalation_level"`
+		User             reference `json:"user"`
+		Start            *string   `json:"start"`
+		End              *string   `json:"end"`
+	} `json:"oncalls"`
+	More bool `json:"more"`
+}
+
+type reference struct {
+	ID      string `json:"id"`
+	Summary string `json:"summary"`
+}
+
+// GetOnCallShiftsByDay implements newspaper.OnCallSchedule interface and fetches
+// on-call shifts overlapping the given day.
+func (p PagerDuty) GetOnCallShiftsByDay(t time.Time) ([]newspaper.OnCallShift, error) {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	end := start.AddDate(0, 0, 1)
+
+	query := url.Values{
+		"since": {start.Format(time.RFC3339)},
+		"until": {end.Format(time.RFC3339)},
+		"limit": {strconv.Itoa(pageSize)},
+	}
+	for _, id := range p.escalationPolicyIDs {
+		query.Add("escalation_policy_ids[]", id)
+	}
+
+	var shifts []newspaper.OnCallShift
+
+	for offset := 0; ; offset += pageSize {
+		query.Set("offset", strconv.Itoa(offset))
+
+		var resp oncallsResponse
+		if err := p.get("/oncalls", query, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, o := range resp.Oncalls {
+			s := newspaper.OnCallShift{
+				EscalationPolicy: o.EscalationPolicy.Summary,
+				Level:            o.EscalationLevel,
+				PersonName:       o.User.Summary,
+			}
+
+			// Permanent on-call assignments come without start and end times.
+			if o.Start != nil {
+				s.StartsAt, _ = time.Parse(time.RFC3339, *o.Start)
+			}
+			if o.End != nil {
+				s.EndsAt, _ = time.Parse(time.RFC3339, *o.End)
+			}
+
+			shifts = append(shifts, s)
+		}
+
+		if !resp.More {
+			break
+		}
+	}
+
+	return shifts, nil
+}
+
+// get sends a GET request to the given path of the API and decodes the response
+// into v.
+func (p PagerDuty) get(path string, query url.Values, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, p.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return errors.Wrap(err, "could not prepare request")
+	}
+
+	req.Header.Set("Accept", "application/vnd.pagerduty+json;version=2")
+	req.Header.Set("Authorization", "Token token="+p.apiToken)
+
+	return httpjson.Do(p.client, req, v)
+}
+
+// incidentLookback is how far back resolved incidents are looked for. PagerDuty
+// filters incidents by the time they were created, so incidents resolved since
+// a given time may have been created long before it.
+const incidentLookback = 7 * 24 * time.Hour
+
+type incidentsResponse struct {
+	Incidents []struct {
+		ID                 string     `json:"id"`
+		IncidentNumber     int        `json:"incident_number"`
+		Title              string     `json:"title"`
+		Status             string     `json:"status"`
+		HTMLURL            string     `json:"html_url"`
+		CreatedAt          time.Time  `json:"created_at"`
+		LastStatusChangeAt time.Time  `json:"last_status_change_at"`
+		Priority           *reference `json:"priority"`
+	} `json:"incidents"`
+	More bool `json:"more"`
+}
+
+// GetIncidentsSince implements newspaper.IncidentSource interface and fetches
+// incidents that were triggered or resolved since the given time as well as the
+// ones that are still open. Severity is taken from the incident's priority.
+func (p PagerDuty) GetIncidentsSince(t time.Time) ([]newspaper.Incident, error) {
+	open := url.Values{
+		"date_range": {"all"},
+		"statuses[]": {"triggered", "acknowledged"},
+	}
+
+	recent := url.Values{
+		"since": {t.Add(-incidentLookback).Format(time.RFC3339)},
+		"until": {newspaper.TimeNowFunc().Format(time.RFC3339)},
+	}
+
+	seen := make(map[string]bool)
+	var incidents []newspaper.Incident
+
+	for _, query := range []url.Values{open, recent} {
+		query.Set("limit", strconv.Itoa(pageSize))
+		for _, id := range p.escalationPolicyIDs {
+			query.Add("escalation_policy_ids[]", id)
+		}
+
+		for offset := 0; ; offset += pageSize {
+			query.Set("offset", strconv.Itoa(offset))
+
+			var resp incidentsResponse
+			if err := p.get("/incidents", query, &resp); err != nil {
+				return nil, err
+			}
+
+			for _, i := range resp.Incidents {
+				if seen[i.ID] {
+					continue
+				}
+				seen[i.ID] = true
+
+				incident := newspaper.Incident{
+					ID:       "#" + strconv.Itoa(i.IncidentNumber),
+					URL:      i.HTMLURL,
+					Title:    i.Title,
+					OpenedAt: i.CreatedAt,
+				}
+
+				if i.Priority != nil {
+					incident.Severity = newspaper.ParseSeverity(i.Priority.Summary)
+				}
+
+				if i.Status == "resolved" {
+					// Resolving is the last status change an incident goes through.
+					incident.ResolvedAt = i.LastStatusChangeAt
+
+					if incident.ResolvedAt.Before(t) {
+						continue
+					}
+				}
+
+				incidents = append(incidents, incident)
+			}
+
+			if !resp.More {
+				break
+			}
+		}
+	}
+
+	return incidents, nil
+}
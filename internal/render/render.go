@@ -0,0 +1,79 @@
+// Package render provides renderings of the newspaper's pages shared by
+// publishers.
+package render
+
+import (
+	"html/template"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+// Headline returns the headline of the given page prefixed with the Unicode
+// character of its emoji. Emoji without a known Unicode character are omitted.
+func Headline(page newspaper.Page) string {
+	if emoji, ok := mrkdwn.EmojiUnicode(page.HeadlineEmojiName); ok {
+		return emoji + " " + page.HeadlineText
+	}
+	return page.HeadlineText
+}
+
+// PlainText renders the given page as plain text with its headline, content and
+// author on separate lines.
+func PlainText(page newspaper.Page) string {
+	lines := []string{page.HeadlineText}
+
+	for _, text := range page.ContentTexts() {
+		lines = append(lines, mrkdwn.PlainText(text))
+	}
+
+	lines = append(lines, "By "+page.AuthorName)
+
+	return strings.Join(lines, "\n")
+}
+
+// HTMLPage represents a page rendered as HTML. Only the content is HTML, while
+// the rest is plain text left for templates to escape.
+type HTMLPage struct {
+	Headline string
+	Content  []template.HTML
+	Author   string
+}
+
+// HTMLPages renders pages of the given issue as HTML.
+func HTMLPages(issue newspaper.Issue) []HTMLPage {
+	var pages []HTMLPage
+
+	for _, page := range issue {
+		p := HTMLPage{
+			Headline: Headline(page),
+			Author:   page.AuthorName,
+		}
+
+		for _, text := range page.ContentTexts() {
+			p.Content = append(p.Content, HTML(text))
+		}
+
+		pages = append(pages, p)
+	}
+
+	return pages
+}
+
+// HTML converts the given string from Slack's mrkdwn format to HTML that is safe
+// to embed into templates, since mrkdwn.HTML escapes the text and drops links
+// of unsafe schemes.
+func HTML(s string) template.HTML {
+	return template.HTML(mrkdwn.HTML(s))
+}
+
+// Truncate shortens the given string to the given number of characters replacing
+// the last one with an ellipsis.
+func Truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit-1]) + "…"
+}
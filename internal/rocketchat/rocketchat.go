@@ -0,0 +1,103 @@
+package rocketchat
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/httpjson"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+	"github.com/ztimes2/dailybugle/internal/render"
+)
+
+// attachmentColor is the color of the stripe next to the attachments.
+const attachmentColor = "#C62828"
+
+// Webhook provides communication with a certain Rocket.Chat channel through its
+// incoming webhook integration.
+type Webhook struct {
+	client *http.Client
+	url    string
+}
+
+// NewWebhook initializes a new Webhook.
+func NewWebhook(c *http.Client, url string) Webhook {
+	return Webhook{
+		client: c,
+		url:    url,
+	}
+}
+
+// Publish implements newspaper.Publisher interface and publishes the given
+// newspaper issue to the Rocket.Chat channel with one message attachment per
+// page.
+func (w Webhook) Publish(issue newspaper.Issue) error {
+	req, err := httpjson.NewRequest(http.MethodPost, w.url, message{
+		Text:        toText(issue),
+		Attachments: toAttachments(issue),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Rocket.Chat reports some failures with a successful status code.
+	var result struct {
+		Success bool   `json:"success"`
+		Error   string `json:"error"`
+	}
+
+	if err := httpjson.Do(w.client, req, &result); err != nil {
+		return err
+	}
+
+	if !result.Success {
+		return errors.Errorf("unsuccessful response: %s", result.Error)
+	}
+
+	return nil
+}
+
+type message struct {
+	Text        string       `json:"text"`
+	Attachments []attachment `json:"attachments"`
+}
+
+type attachment struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+	Color string `json:"color"`
+}
+
+// toText returns the message's text shown above the attachments and in
+// notifications.
+func toText(issue newspaper.Issue) string {
+	var headlines []string
+	for _, page := range issue {
+		headlines = append(headlines, "*"+page.HeadlineText+"*")
+	}
+	return "The Daily Bugle: " + strings.Join(headlines, " · ")
+}
+
+// toAttachments turns the given newspaper issue into message attachments where
+// each page gets its own attachment. Rocket.Chat does not support footers of
+// attachments, so the author is mentioned at the end of the text instead.
+func toAttachments(issue newspaper.Issue) []attachment {
+	var attachments []attachment
+
+	for _, page := range issue {
+		var texts []string
+		for _, text := range page.ContentTexts() {
+			texts = append(texts, mrkdwn.RocketChat(text))
+		}
+		texts = append(texts, "_By "+page.AuthorName+"_")
+
+		attachments = append(attachments, attachment{
+			Title: render.Headline(page),
+			Text:  strings.Join(texts, "\n\n"),
+			Color: attachmentColor,
+		})
+	}
+
+	return attachments
+}
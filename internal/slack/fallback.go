@@ -0,0 +1,57 @@
+package slack
+
+import (
+	"strings"
+
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+	"github.com/ztimes2/dailybugle/internal/render"
+)
+
+// maxFallbackTextLength is the number of characters after which fallback texts
+// get truncated. Slack only shows the beginning of a text in notifications, so
+// there is no point in sending the entire issue twice.
+const maxFallbackTextLength = 4000
+
+// toFallbackText renders the given issue as plain text. The text opens with the
+// front page so that notification previews show the headlines first.
+func toFallbackText(issue newspaper.Issue) string {
+	parts := []string{toFrontPageFallbackText(issue)}
+
+	for _, page := range issue {
+		parts = append(parts, toPageFallbackText(page))
+	}
+
+	return render.Truncate(strings.Join(parts, "\n\n"), maxFallbackTextLength)
+}
+
+// toFrontPageFallbackText renders headlines and summaries of the pages of the
+// given issue as plain text.
+func toFrontPageFallbackText(issue newspaper.Issue) string {
+	var lines []string
+
+	for _, page := range issue {
+		line := page.HeadlineText
+
+		if summary := mrkdwn.PlainText(getPageSummary(page)); summary != "" {
+			line += ": " + summary
+		}
+
+		lines = append(lines, line)
+	}
+
+	return render.Truncate(strings.Join(lines, "\n"), maxFallbackTextLength)
+}
+
+// toPageFallbackText renders the given page as plain text.
+func toPageFallbackText(page newspaper.Page) string {
+	return render.Truncate(render.PlainText(page), maxFallbackTextLength)
+}
+
+// getIssueHeadline returns the headline of the given issue's front page.
+func getIssueHeadline(issue newspaper.Issue) string {
+	if len(issue) == 0 {
+		return ""
+	}
+	return issue[0].HeadlineText
+}
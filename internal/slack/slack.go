@@ -1,6 +1,9 @@
 package slack
 
 import (
+	"strings"
+
+	"github.com/pkg/errors"
 	"github.com/slack-go/slack"
 	"github.com/ztimes2/dailybugle/internal/mrkdwn"
 	"github.com/ztimes2/dailybugle/internal/newspaper"
@@ -10,67 +13,377 @@ import (
 type Channel struct {
 	client    *slack.Client
 	channelID string
+
+	ledger   newspaper.Ledger
+	edition  string
+	force    bool
+	threaded bool
+}
+
+// ChannelOption is used for customizing Channel's behaviour.
+type ChannelOption func(*Channel)
+
+// WithLedger makes the Channel keep records of published issues of the given
+// edition in the ledger and skip issues that have already been published to
+// the channel on the same day.
+func WithLedger(l newspaper.Ledger, edition string) ChannelOption {
+	return func(c *Channel) {
+		c.ledger = l
+		c.edition = edition
+	}
+}
+
+// WithForce makes the Channel publish issues even if the same edition has already
+// been published on the same day. In such case the previously posted message is
+// updated in place with the latest content of the issue.
+func WithForce(force bool) ChannelOption {
+	return func(c *Channel) {
+		c.force = force
+	}
+}
+
+// WithThreads makes the Channel publish issues as a compact front page containing
+// headlines and summaries of the pages, while the pages themselves are posted
+// to the front page's thread.
+func WithThreads(threaded bool) ChannelOption {
+	return func(c *Channel) {
+		c.threaded = threaded
+	}
 }
 
 // NewChannel initializes a new Channel.
-func NewChannel(apiToken, channelID string) Channel {
-	return Channel{
+func NewChannel(apiToken, channelID string, opts ...ChannelOption) Channel {
+	c := Channel{
 		client:    slack.New(apiToken),
 		channelID: channelID,
 	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c
 }
 
 // Publish edits and publishes the given newspaper issue to the Slack channel.
+// An issue exceeding Slack's limits is split into several messages where the
+// first one is posted to the channel and the rest are posted to its thread.
+//
+// If the Channel keeps a ledger, then an issue that has already been published
+// on the same day is skipped with newspaper.ErrIssueAlreadyPublished unless
+// publishing is forced, in which case the previously posted messages are updated
+// instead of posting new ones. Forcing fails with
+// newspaper.ErrPublicationInProgress while another run is still publishing the
+// issue.
 func (c Channel) Publish(issue newspaper.Issue) error {
-	blocks := []slack.Block{
-		// Adds a small empty space before the very first page.
-		slack.NewSectionBlock(
-			slack.NewTextBlockObject(slack.MarkdownType, " ", false, false),
-			nil, nil,
-		),
+	messages := c.toMessages(issue)
+
+	if c.ledger == nil {
+		_, err := c.post(messages)
+		return err
+	}
+
+	date := newspaper.TimeNowFunc()
+
+	existing, reserved, err := c.ledger.ReservePublication(c.edition, c.channelID, date)
+	if err != nil {
+		return errors.Wrap(err, "could not reserve publication")
+	}
+
+	if !reserved && !c.force {
+		return newspaper.ErrIssueAlreadyPublished
+	}
+
+	if !reserved {
+		if existing.IsPending() {
+			// Posting now would duplicate the issue and overwrite the record
+			// of the run that is publishing it.
+			return newspaper.ErrPublicationInProgress
+		}
+		return c.republish(existing, messages)
+	}
+
+	timestamps, err := c.post(messages)
+	if err != nil {
+		// Releases the claim so that a retry is able to publish the issue.
+		_ = c.ledger.CancelPublication(c.edition, c.channelID, date)
+		return err
+	}
+
+	if err := c.ledger.SavePublication(newspaper.Publication{
+		Edition:           c.edition,
+		Destination:       c.channelID,
+		Date:              date,
+		PublishedAt:       newspaper.TimeNowFunc(),
+		ChannelID:         c.channelID,
+		MessageTimestamps: timestamps,
+	}); err != nil {
+		return errors.Wrap(err, "could not save publication")
+	}
+
+	return nil
+}
+
+// republish updates the messages of the given publication with the given ones.
+// Messages that did not exist before are posted to the thread of the first one
+// and messages that are no longer needed are deleted. The publication is saved
+// even if republishing fails halfway, so that messages posted in the meantime
+// are not posted again by the next run, while messages that were not reached or
+// could not be deleted are kept in the record for the next run to take care of.
+func (c Channel) republish(p newspaper.Publication, messages []message) error {
+	var (
+		timestamps []string
+		err        error
+	)
+
+	for i, m := range messages {
+		var timestamp string
+		if timestamp, err = c.republishMessage(p, i, m); err != nil {
+			break
+		}
+		timestamps = append(timestamps, timestamp)
+	}
+
+	var leftover []string
+	if len(timestamps) < len(p.MessageTimestamps) {
+		leftover = p.MessageTimestamps[len(timestamps):]
+	}
+
+	if err == nil {
+		var undeleted []string
+		for _, timestamp := range leftover {
+			if _, _, delErr := c.client.DeleteMessage(p.ChannelID, timestamp); delErr != nil {
+				err = errors.Wrap(delErr, "could not delete message")
+				undeleted = append(undeleted, timestamp)
+			}
+		}
+		leftover = undeleted
+	}
+
+	p.PublishedAt = newspaper.TimeNowFunc()
+	p.MessageTimestamps = append(timestamps, leftover...)
+
+	if saveErr := c.ledger.SavePublication(p); saveErr != nil && err == nil {
+		err = errors.Wrap(saveErr, "could not save publication")
+	}
+
+	return err
+}
+
+// republishMessage updates the i-th message of the given publication with the
+// given one or posts it to the thread of the first message if the publication
+// has fewer messages. It returns the timestamp of the message.
+func (c Channel) republishMessage(p newspaper.Publication, i int, m message,
+) (string, error) {
+
+	if i < len(p.MessageTimestamps) {
+		if _, _, _, err := c.client.UpdateMessage(
+			p.ChannelID, p.MessageTimestamps[i],
+			slack.MsgOptionText(m.text, true),
+			slack.MsgOptionBlocks(m.blocks...),
+		); err != nil {
+			return "", errors.Wrap(err, "could not update message")
+		}
+
+		return p.MessageTimestamps[i], nil
+	}
+
+	_, timestamp, err := c.client.PostMessage(p.ChannelID,
+		slack.MsgOptionAsUser(true),
+		slack.MsgOptionTS(p.MessageTimestamps[0]),
+		slack.MsgOptionText(m.text, true),
+		slack.MsgOptionBlocks(m.blocks...),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "could not post message")
 	}
 
+	return timestamp, nil
+}
+
+// post posts the given messages to the channel and returns their timestamps.
+// The first message is posted to the channel while the rest are posted to its
+// thread. If any of the messages fails to be posted, then the already posted ones
+// are deleted so that the channel does not end up with an incomplete issue.
+func (c Channel) post(messages []message) ([]string, error) {
+	var timestamps []string
+
+	for _, m := range messages {
+		opts := []slack.MsgOption{
+			slack.MsgOptionAsUser(true),
+			slack.MsgOptionText(m.text, true),
+			slack.MsgOptionBlocks(m.blocks...),
+		}
+
+		if len(timestamps) > 0 {
+			opts = append(opts, slack.MsgOptionTS(timestamps[0]))
+		}
+
+		_, timestamp, err := c.client.PostMessage(c.channelID, opts...)
+		if err != nil {
+			for i := len(timestamps) - 1; i >= 0; i-- {
+				_, _, _ = c.client.DeleteMessage(c.channelID, timestamps[i])
+			}
+			return nil, err
+		}
+
+		timestamps = append(timestamps, timestamp)
+	}
+
+	return timestamps, nil
+}
+
+// message represents a Slack message consisting of blocks and a plain-text
+// fallback shown in notifications and by clients unable to render the blocks.
+type message struct {
+	text   string
+	blocks []slack.Block
+}
+
+// toMessages turns the given newspaper issue into Slack messages where the first
+// message is meant to be posted to the channel and the rest to its thread.
+func (c Channel) toMessages(issue newspaper.Issue) []message {
+	if !c.threaded {
+		groups := [][]slack.Block{
+			{
+				// Adds a small empty space before the very first page.
+				newSpacerBlock(),
+			},
+		}
+
+		for _, page := range issue {
+			groups = append(groups, toPageBlocks(page))
+		}
+
+		groups = append(groups, []slack.Block{
+			// Adds a small empty space after the very last page.
+			newSpacerBlock(),
+		})
+
+		return toMessagesWithText(
+			splitIntoMessages(groups),
+			toFallbackText(issue),
+			getIssueHeadline(issue),
+		)
+	}
+
+	messages := toMessagesWithText(
+		splitIntoMessages([][]slack.Block{toFrontPageBlocks(issue)}),
+		toFrontPageFallbackText(issue),
+		getIssueHeadline(issue),
+	)
+
 	for _, page := range issue {
-		// Turns page components into Slack message blocks.
+		messages = append(messages, toMessagesWithText(
+			splitIntoMessages([][]slack.Block{toPageBlocks(page)}),
+			toPageFallbackText(page),
+			page.HeadlineText,
+		)...)
+	}
 
-		pageBlocks := []slack.Block{
-			slack.NewHeaderBlock(slack.NewTextBlockObject(
-				slack.PlainTextType,
-				mrkdwn.Emoji(page.HeadlineEmojiName)+" "+page.HeadlineText,
-				false, false,
-			)),
+	return messages
+}
+
+// toMessagesWithText pairs the given messages with fallback texts. The first
+// message gets the given text while the rest are marked as its continuation.
+func toMessagesWithText(blocks [][]slack.Block, text, headline string) []message {
+	messages := make([]message, 0, len(blocks))
+
+	for i, b := range blocks {
+		m := message{
+			text:   text,
+			blocks: b,
+		}
+
+		if i > 0 {
+			m.text = headline + " (continued)"
 		}
 
-		pageBlocks = append(pageBlocks, page.ContentElements...)
+		messages = append(messages, m)
+	}
+
+	return messages
+}
+
+// toFrontPageBlocks turns the given newspaper issue into Slack message blocks
+// containing only headlines and summaries of its pages.
+func toFrontPageBlocks(issue newspaper.Issue) []slack.Block {
+	blocks := []slack.Block{
+		// Adds a small empty space before the very first headline.
+		newSpacerBlock(),
+	}
 
-		pageBlocks = append(pageBlocks, slack.NewContextBlock(
-			"",
+	for _, page := range issue {
+		blocks = append(blocks, slack.NewSectionBlock(
 			slack.NewTextBlockObject(
 				slack.MarkdownType,
-				mrkdwn.Italic("By "+page.AuthorName),
+				mrkdwn.Bold(mrkdwn.Emoji(page.HeadlineEmojiName)+" "+page.HeadlineText)+
+					"\n"+getPageSummary(page),
 				false, false,
 			),
+			nil, nil,
 		))
+	}
 
-		pageBlocks = append(pageBlocks, slack.NewDividerBlock())
+	blocks = append(blocks, slack.NewContextBlock(
+		"",
+		slack.NewTextBlockObject(
+			slack.MarkdownType,
+			mrkdwn.Italic("Full stories are in the thread."),
+			false, false,
+		),
+	))
 
-		blocks = append(blocks, pageBlocks...)
+	return blocks
+}
+
+// getPageSummary returns a one-line summary of the given page. Pages that do not
+// provide a summary are summarized by the first line of their content.
+func getPageSummary(page newspaper.Page) string {
+	if page.SummaryText != "" {
+		return page.SummaryText
 	}
 
-	blocks = append(blocks,
-		// Adds a small empty space after the very last page.
-		slack.NewSectionBlock(
-			slack.NewTextBlockObject(slack.MarkdownType, " ", false, false),
-			nil, nil,
-		),
-	)
+	for _, text := range page.ContentTexts() {
+		for _, line := range strings.Split(text, "\n") {
+			if strings.TrimSpace(line) != "" {
+				return line
+			}
+		}
+	}
 
-	if _, _, err := c.client.PostMessage(c.channelID,
-		slack.MsgOptionAsUser(true),
-		slack.MsgOptionBlocks(blocks...),
-	); err != nil {
-		return err
+	return ""
+}
+
+// toPageBlocks turns the given newspaper page into Slack message blocks.
+func toPageBlocks(page newspaper.Page) []slack.Block {
+	pageBlocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(
+			slack.PlainTextType,
+			mrkdwn.Emoji(page.HeadlineEmojiName)+" "+page.HeadlineText,
+			false, false,
+		)),
 	}
 
-	return nil
+	pageBlocks = append(pageBlocks, splitLongSections(page.ContentElements)...)
+
+	pageBlocks = append(pageBlocks, slack.NewContextBlock(
+		"",
+		slack.NewTextBlockObject(
+			slack.MarkdownType,
+			mrkdwn.Italic("By "+page.AuthorName),
+			false, false,
+		),
+	))
+
+	pageBlocks = append(pageBlocks, slack.NewDividerBlock())
+
+	return pageBlocks
+}
+
+func newSpacerBlock() slack.Block {
+	return slack.NewSectionBlock(
+		slack.NewTextBlockObject(slack.MarkdownType, " ", false, false),
+		nil, nil,
+	)
 }
@@ -0,0 +1,93 @@
+package slack
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+)
+
+const (
+	// maxBlocksPerMessage is the maximum number of blocks Slack accepts in
+	// a single message.
+	maxBlocksPerMessage = 50
+
+	// maxSectionTextLength is the maximum number of characters Slack accepts in
+	// a text of a single section block.
+	maxSectionTextLength = 3000
+)
+
+// splitIntoMessages packs the given groups of blocks into messages that do not
+// exceed Slack's limit of blocks per message. Groups are kept within a single
+// message whenever possible and are only broken apart when a group alone does
+// not fit into a message.
+func splitIntoMessages(groups [][]slack.Block) [][]slack.Block {
+	var (
+		messages [][]slack.Block
+		current  []slack.Block
+	)
+
+	for _, group := range groups {
+		if len(current)+len(group) > maxBlocksPerMessage && len(current) > 0 {
+			messages = append(messages, current)
+			current = nil
+		}
+
+		for len(group) > maxBlocksPerMessage {
+			messages = append(messages, group[:maxBlocksPerMessage])
+			group = group[maxBlocksPerMessage:]
+		}
+
+		current = append(current, group...)
+	}
+
+	if len(current) > 0 {
+		messages = append(messages, current)
+	}
+
+	return messages
+}
+
+// splitLongSections replaces section blocks which text exceeds Slack's limit with
+// several section blocks carrying chunks of the original text.
+func splitLongSections(blocks []slack.Block) []slack.Block {
+	var split []slack.Block
+
+	for _, b := range blocks {
+		section, ok := b.(*slack.SectionBlock)
+		if !ok || section.Text == nil ||
+			utf8.RuneCountInString(section.Text.Text) <= maxSectionTextLength {
+
+			split = append(split, b)
+			continue
+		}
+
+		first := true
+
+		for _, chunk := range mrkdwn.Chunk(section.Text.Text, maxSectionTextLength) {
+			// Slack rejects sections with blank text.
+			if strings.TrimSpace(chunk) == "" {
+				continue
+			}
+
+			s := *section
+			s.Text = slack.NewTextBlockObject(
+				section.Text.Type, chunk, section.Text.Emoji, section.Text.Verbatim,
+			)
+
+			// Keeps the accessory and fields next to the first chunk only and
+			// leaves block IDs unique.
+			if !first {
+				s.Accessory = nil
+				s.Fields = nil
+				s.BlockID = ""
+			}
+			first = false
+
+			split = append(split, &s)
+		}
+	}
+
+	return split
+}
@@ -0,0 +1,133 @@
+package teams
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ztimes2/dailybugle/internal/httpjson"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+	"github.com/ztimes2/dailybugle/internal/render"
+)
+
+const (
+	adaptiveCardContentType = "application/vnd.microsoft.card.adaptive"
+	adaptiveCardSchema      = "http://adaptivecards.io/schemas/adaptive-card.json"
+	adaptiveCardVersion     = "1.4"
+)
+
+// Channel provides communication with a certain Microsoft Teams channel through
+// its incoming webhook or workflow URL.
+type Channel struct {
+	client     *http.Client
+	webhookURL string
+}
+
+// NewChannel initializes a new Channel.
+func NewChannel(c *http.Client, webhookURL string) Channel {
+	return Channel{
+		client:     c,
+		webhookURL: webhookURL,
+	}
+}
+
+// Publish implements newspaper.Publisher interface and publishes the given
+// newspaper issue to the Teams channel as an Adaptive Card.
+func (c Channel) Publish(issue newspaper.Issue) error {
+	return httpjson.Post(c.client, c.webhookURL, message{
+		Type: "message",
+		Attachments: []attachment{
+			{
+				ContentType: adaptiveCardContentType,
+				Content:     toAdaptiveCard(issue),
+			},
+		},
+	})
+}
+
+type message struct {
+	Type        string       `json:"type"`
+	Attachments []attachment `json:"attachments"`
+}
+
+type attachment struct {
+	ContentType string       `json:"contentType"`
+	Content     adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string    `json:"$schema"`
+	Type    string    `json:"type"`
+	Version string    `json:"version"`
+	Body    []element `json:"body"`
+	MSTeams msTeams   `json:"msteams"`
+}
+
+type msTeams struct {
+	Width string `json:"width"`
+}
+
+// element represents either a TextBlock or a Container element of an Adaptive
+// Card.
+type element struct {
+	Type      string    `json:"type"`
+	Text      string    `json:"text,omitempty"`
+	Size      string    `json:"size,omitempty"`
+	Weight    string    `json:"weight,omitempty"`
+	IsSubtle  bool      `json:"isSubtle,omitempty"`
+	Wrap      bool      `json:"wrap,omitempty"`
+	Separator bool      `json:"separator,omitempty"`
+	Spacing   string    `json:"spacing,omitempty"`
+	Items     []element `json:"items,omitempty"`
+}
+
+// toAdaptiveCard turns the given newspaper issue into an Adaptive Card where each
+// page is rendered as a separate container.
+func toAdaptiveCard(issue newspaper.Issue) adaptiveCard {
+	card := adaptiveCard{
+		Schema:  adaptiveCardSchema,
+		Type:    "AdaptiveCard",
+		Version: adaptiveCardVersion,
+		MSTeams: msTeams{
+			Width: "Full",
+		},
+	}
+
+	for i, page := range issue {
+		items := []element{
+			{
+				Type:   "TextBlock",
+				Text:   render.Headline(page),
+				Size:   "Large",
+				Weight: "Bolder",
+				Wrap:   true,
+			},
+		}
+
+		for _, text := range page.ContentTexts() {
+			items = append(items, element{
+				Type: "TextBlock",
+				// Teams only breaks lines separated by an empty line.
+				Text: strings.ReplaceAll(mrkdwn.Markdown(text), "\n", "\n\n"),
+				Wrap: true,
+			})
+		}
+
+		items = append(items, element{
+			Type:     "TextBlock",
+			Text:     "_By " + page.AuthorName + "_",
+			Size:     "Small",
+			IsSubtle: true,
+			Wrap:     true,
+		})
+
+		card.Body = append(card.Body, element{
+			Type:      "Container",
+			Items:     items,
+			Separator: i > 0,
+			Spacing:   "Large",
+		})
+	}
+
+	return card
+}
@@ -0,0 +1,111 @@
+package teams
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+func TestChannel_Publish(t *testing.T) {
+	var (
+		method      string
+		contentType string
+		msg         message
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		contentType = r.Header.Get("Content-Type")
+
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("could not decode request: %v", err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	issue := newspaper.Issue{
+		{
+			HeadlineEmojiName: "newspaper",
+			HeadlineText:      "Front page",
+			AuthorName:        "Peter Parker",
+			ContentElements: []slack.Block{
+				slack.NewSectionBlock(slack.NewTextBlockObject(
+					slack.MarkdownType, "*Hello*\n<https://example.com|world>", false, false,
+				), nil, nil),
+			},
+		},
+		{
+			HeadlineText: "Second page",
+			AuthorName:   "Mary Jane",
+		},
+	}
+
+	if err := NewChannel(srv.Client(), srv.URL).Publish(issue); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if method != http.MethodPost {
+		t.Errorf("expected method %s, got %s", http.MethodPost, method)
+	}
+	if contentType != "application/json" {
+		t.Errorf("expected content type application/json, got %s", contentType)
+	}
+
+	if msg.Type != "message" || len(msg.Attachments) != 1 {
+		t.Fatalf("expected a message with a single attachment, got %+v", msg)
+	}
+
+	a := msg.Attachments[0]
+	if a.ContentType != adaptiveCardContentType {
+		t.Errorf("expected content type %s, got %s", adaptiveCardContentType, a.ContentType)
+	}
+	if a.Content.Type != "AdaptiveCard" || a.Content.Version != adaptiveCardVersion {
+		t.Errorf("unexpected card %s %s", a.Content.Type, a.Content.Version)
+	}
+	if len(a.Content.Body) != 2 {
+		t.Fatalf("expected a container per page, got %d", len(a.Content.Body))
+	}
+
+	first := a.Content.Body[0]
+	if first.Type != "Container" || first.Separator {
+		t.Errorf("unexpected first container %+v", first)
+	}
+
+	expected := []string{
+		"📰 Front page",
+		"**Hello**\n\n[world](https://example.com)",
+		"_By Peter Parker_",
+	}
+	if len(first.Items) != len(expected) {
+		t.Fatalf("expected %d items, got %+v", len(expected), first.Items)
+	}
+	for i, text := range expected {
+		if first.Items[i].Text != text {
+			t.Errorf("expected item %d to be %q, got %q", i, text, first.Items[i].Text)
+		}
+	}
+
+	if !a.Content.Body[1].Separator {
+		t.Error("expected the second container to be separated")
+	}
+}
+
+func TestChannel_Publish_UnexpectedResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "webhook is disabled", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	err := NewChannel(srv.Client(), srv.URL).Publish(newspaper.Issue{
+		{HeadlineText: "Front page"},
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,148 @@
+package testhistory
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ztimes2/dailybugle/internal/atomicfile"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+const defaultRetention = 90 * 24 * time.Hour
+
+// File implements newspaper.TestResultStore interface and keeps history of test
+// results in a local JSON file. Results older than the retention period are
+// dropped whenever new results are saved.
+type File struct {
+	path      string
+	retention time.Duration
+}
+
+// NewFile initializes a new File. The retention defaults to 90 days.
+func NewFile(path string, retention time.Duration) File {
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+
+	return File{
+		path:      path,
+		retention: retention,
+	}
+}
+
+type record struct {
+	Suite  string    `json:"suite"`
+	Name   string    `json:"name"`
+	Commit string    `json:"commit"`
+	Passed bool      `json:"passed"`
+	RanAt  time.Time `json:"ran_at"`
+}
+
+func (r record) key() string {
+	return r.Suite + "\x00" + r.Name + "\x00" + r.Commit + "\x00" +
+		r.RanAt.UTC().Format(time.RFC3339Nano) + "\x00" + strconv.FormatBool(r.Passed)
+}
+
+// GetTestResultsSince implements newspaper.TestResultSource interface and reads
+// results of tests that ran since the given time.
+func (f File) GetTestResultsSince(t time.Time) ([]newspaper.TestResult, error) {
+	records, err := f.read()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read test history")
+	}
+
+	var results []newspaper.TestResult
+
+	for _, r := range records {
+		if r.RanAt.Before(t) {
+			continue
+		}
+
+		results = append(results, newspaper.TestResult{
+			Suite:  r.Suite,
+			Name:   r.Name,
+			Commit: r.Commit,
+			Passed: r.Passed,
+			RanAt:  r.RanAt,
+		})
+	}
+
+	return results, nil
+}
+
+// SaveTestResults implements newspaper.TestResultStore interface and adds the
+// given results to the history. Results of the same test that ran on the same
+// commit at the same time with the same outcome are considered to be already
+// saved.
+func (f File) SaveTestResults(results []newspaper.TestResult) error {
+	records, err := f.read()
+	if err != nil {
+		return errors.Wrap(err, "could not read test history")
+	}
+
+	cutoff := newspaper.TimeNowFunc().Add(-f.retention)
+
+	var kept []record
+	seen := make(map[string]bool)
+
+	for _, r := range records {
+		if r.RanAt.Before(cutoff) {
+			continue
+		}
+		seen[r.key()] = true
+		kept = append(kept, r)
+	}
+
+	for _, res := range results {
+		r := record{
+			Suite:  res.Suite,
+			Name:   res.Name,
+			Commit: res.Commit,
+			Passed: res.Passed,
+			RanAt:  res.RanAt,
+		}
+
+		if r.RanAt.Before(cutoff) || seen[r.key()] {
+			continue
+		}
+		seen[r.key()] = true
+		kept = append(kept, r)
+	}
+
+	if err := f.write(kept); err != nil {
+		return errors.Wrap(err, "could not write test history")
+	}
+
+	return nil
+}
+
+func (f File) read() ([]record, error) {
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var records []record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// write atomically replaces the history's file with the given records.
+func (f File) write(records []record) error {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(f.path, data, 0644)
+}
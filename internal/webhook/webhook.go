@@ -0,0 +1,208 @@
+// Package webhook provides publishing of the newspaper's issues to arbitrary HTTP
+// endpoints as signed JSON documents.
+//
+// Each issue is sent in a POST request with a JSON body of the following shape
+// (version 1):
+//
+//	{
+//	  "version": "1",
+//	  "date": "2021-02-15",
+//	  "published_at": "2021-02-15T09:00:00+08:00",
+//	  "pages": [
+//	    {
+//	      "headline": "Code Review Market",
+//	      "headline_emoji": {"name": "chart_with_upwards_trend", "unicode": "📈"},
+//	      "summary": "3 tickets are waiting for code review, the oldest one for 5 days.",
+//	      "author": "J. Jonah Jameson",
+//	      "content": [
+//	        {
+//	          "mrkdwn": "...",
+//	          "plain_text": "...",
+//	          "markdown": "...",
+//	          "html": "...",
+//	          "links": [{"text": "MB-1", "url": "https://jira.example.com/browse/MB-1"}]
+//	        }
+//	      ],
+//	      "blocks": [...]
+//	    }
+//	  ]
+//	}
+//
+// Content of a page is provided in several formats, while "blocks" carries the
+// original Slack Block Kit elements for consumers needing the full structure.
+// Fields may be added within the same version, but never removed or changed.
+//
+// Requests carry the following headers:
+//
+//	X-Dailybugle-Version:   version of the payload
+//	X-Dailybugle-Timestamp: Unix time of the request
+//	X-Dailybugle-Signature: sha256=<hex-encoded HMAC-SHA256>
+//
+// The signature is computed with the shared secret over the timestamp, a dot and
+// the raw body (e.g. "1613350800.{...}"). Consumers should recompute it, compare
+// it in constant time and reject requests with timestamps that are too old.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/ztimes2/dailybugle/internal/httpjson"
+	"github.com/ztimes2/dailybugle/internal/mrkdwn"
+	"github.com/ztimes2/dailybugle/internal/newspaper"
+)
+
+const (
+	// Version is the version of the payload's format.
+	Version = "1"
+
+	// HeaderVersion is the header carrying the version of the payload's format.
+	HeaderVersion = "X-Dailybugle-Version"
+
+	// HeaderTimestamp is the header carrying Unix time of the request.
+	HeaderTimestamp = "X-Dailybugle-Timestamp"
+
+	// HeaderSignature is the header carrying the request's signature.
+	HeaderSignature = "X-Dailybugle-Signature"
+)
+
+// Endpoint provides communication with a certain HTTP endpoint consuming the
+// newspaper's issues.
+type Endpoint struct {
+	client *http.Client
+	url    string
+	secret string
+}
+
+// NewEndpoint initializes a new Endpoint. Requests are signed with the given
+// secret.
+func NewEndpoint(c *http.Client, url, secret string) Endpoint {
+	return Endpoint{
+		client: c,
+		url:    url,
+		secret: secret,
+	}
+}
+
+// Publish implements newspaper.Publisher interface and sends the given newspaper
+// issue to the endpoint.
+func (e Endpoint) Publish(issue newspaper.Issue) error {
+	now := newspaper.TimeNowFunc()
+
+	body, err := json.Marshal(toPayload(issue, now))
+	if err != nil {
+		return errors.Wrap(err, "could not encode payload")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "could not prepare request")
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderVersion, Version)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(e.secret, timestamp, body))
+
+	return httpjson.Do(e.client, req, nil)
+}
+
+// Sign returns a signature of a request with the given timestamp and body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type payload struct {
+	Version     string        `json:"version"`
+	Date        string        `json:"date"`
+	PublishedAt time.Time     `json:"published_at"`
+	Pages       []payloadPage `json:"pages"`
+}
+
+type payloadPage struct {
+	Headline      string           `json:"headline"`
+	HeadlineEmoji payloadEmoji     `json:"headline_emoji"`
+	Summary       string           `json:"summary,omitempty"`
+	Author        string           `json:"author"`
+	Content       []payloadContent `json:"content"`
+	Blocks        []slack.Block    `json:"blocks"`
+}
+
+type payloadEmoji struct {
+	Name    string `json:"name"`
+	Unicode string `json:"unicode,omitempty"`
+}
+
+type payloadContent struct {
+	Mrkdwn    string        `json:"mrkdwn"`
+	PlainText string        `json:"plain_text"`
+	Markdown  string        `json:"markdown"`
+	HTML      string        `json:"html"`
+	Links     []payloadLink `json:"links"`
+}
+
+type payloadLink struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+func toPayload(issue newspaper.Issue, t time.Time) payload {
+	p := payload{
+		Version:     Version,
+		Date:        t.Format("2006-01-02"),
+		PublishedAt: t,
+		Pages:       []payloadPage{},
+	}
+
+	for _, page := range issue {
+		pp := payloadPage{
+			Headline: page.HeadlineText,
+			HeadlineEmoji: payloadEmoji{
+				Name: page.HeadlineEmojiName,
+			},
+			Summary: mrkdwn.PlainText(page.SummaryText),
+			Author:  page.AuthorName,
+			Content: []payloadContent{},
+			Blocks:  page.ContentElements,
+		}
+
+		pp.HeadlineEmoji.Unicode, _ = mrkdwn.EmojiUnicode(page.HeadlineEmojiName)
+
+		if pp.Blocks == nil {
+			pp.Blocks = []slack.Block{}
+		}
+
+		for _, text := range page.ContentTexts() {
+			c := payloadContent{
+				Mrkdwn:    text,
+				PlainText: mrkdwn.PlainText(text),
+				Markdown:  mrkdwn.Markdown(text),
+				HTML:      mrkdwn.HTML(text),
+				Links:     []payloadLink{},
+			}
+
+			for _, l := range mrkdwn.Links(text) {
+				c.Links = append(c.Links, payloadLink{Text: l.Text, URL: l.URL})
+			}
+
+			pp.Content = append(pp.Content, c)
+		}
+
+		p.Pages = append(p.Pages, pp)
+	}
+
+	return p
+}